@@ -0,0 +1,44 @@
+package gofastapi
+
+import (
+	"encoding/base64"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// BasicCredentials holds credentials parsed from a Basic Authorization header
+type BasicCredentials struct {
+	Username string
+	Password string
+}
+
+// ParseBasicAuth parses an Authorization header value of the form "Basic <base64(user:pass)>"
+func ParseBasicAuth(token string) (user, pass string, ok bool) {
+	const prefix = "Basic "
+	if len(token) < len(prefix) || !strings.EqualFold(token[:len(prefix)], prefix) {
+		return "", "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(token[len(prefix):]))
+	if err != nil {
+		return "", "", false
+	}
+	user, pass, ok = strings.Cut(string(decoded), ":")
+	if !ok {
+		return "", "", false
+	}
+	return user, pass, true
+}
+
+// BasicAuthExtractor extracts Basic credentials from the Authorization header
+type BasicAuthExtractor struct {
+	fieldType reflect.Type
+}
+
+func (e *BasicAuthExtractor) Extract(r *http.Request, vars map[string]string, body []byte) (interface{}, error) {
+	user, pass, ok := ParseBasicAuth(r.Header.Get("Authorization"))
+	if !ok {
+		return nil, NewError(http.StatusUnauthorized, "Invalid or missing basic authentication credentials")
+	}
+	return BasicCredentials{Username: user, Password: pass}, nil
+}
@@ -0,0 +1,321 @@
+package gofastapi
+
+import (
+	"bytes"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// CachedResponse is a response stored by CacheMiddleware. For responses with a Vary
+// header, the request key holds an entry with Status 0 whose Header["Vary"] lists the
+// varying request headers, and the response is stored under a key extended with
+// their values.
+type CachedResponse struct {
+	Status      int
+	Header      http.Header
+	Body        []byte
+	RequestHash []byte // SHA-256 of the request body, for responses stored by IdempotencyMiddleware
+}
+
+// CacheStore stores cached responses, e.g. in memory or Redis
+type CacheStore interface {
+	// Get returns the response stored under key, if present and not expired
+	Get(key string) (*CachedResponse, bool)
+	// Set stores a response under key for ttl
+	Set(key string, resp *CachedResponse, ttl time.Duration)
+}
+
+// CacheOption configures CacheMiddleware
+type CacheOption func(*cacheConfig)
+
+type cacheConfig struct {
+	store CacheStore
+	vary  []string
+}
+
+// WithCacheStore sets the store used by CacheMiddleware (default: in memory)
+func WithCacheStore(store CacheStore) CacheOption {
+	return func(c *cacheConfig) {
+		c.store = store
+	}
+}
+
+// WithCacheVary caches responses separately per value of the given request headers,
+// e.g. "Authorization" for per-user responses
+func WithCacheVary(headers ...string) CacheOption {
+	return func(c *cacheConfig) {
+		c.vary = append(c.vary, headers...)
+	}
+}
+
+// varies reports whether the configured Vary headers include name
+func (c *cacheConfig) varies(name string) bool {
+	for _, header := range c.vary {
+		if strings.EqualFold(header, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// CacheMiddleware caches successful GET and HEAD responses for ttl, keyed by method,
+// path, query, the configured Vary headers and those named by the response's own
+// Vary header. Requests carrying Authorization or Cookie bypass the cache unless
+// WithCacheVary names that header. Non-200 responses, SSE streams, responses setting
+// cookies, "Vary: *" and "Cache-Control: no-store", "private" or "no-cache" are not
+// cached. Per-request headers (X-Request-ID and those named by private="..." or
+// no-cache="...") are not stored. Responses carry X-Cache: HIT or MISS.
+func CacheMiddleware(ttl time.Duration, opts ...CacheOption) mux.MiddlewareFunc {
+	cfg := &cacheConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.store == nil {
+		cfg.store = NewMemoryCacheStore()
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Credentials make responses per-user, so only cache them when keyed by
+			if (r.Header.Get("Authorization") != "" && !cfg.varies("Authorization")) ||
+				(r.Header.Get("Cookie") != "" && !cfg.varies("Cookie")) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := cacheKey(r, cfg.vary)
+			cached, ok := cfg.store.Get(key)
+			if ok && cached.Status == 0 {
+				// The responses vary by request headers; look up this request's variant
+				cached, ok = cfg.store.Get(cacheKey(r, append(slices.Clip(cfg.vary), cached.Header["Vary"]...)))
+			}
+			if ok {
+				for name, values := range cached.Header {
+					w.Header()[name] = append([]string(nil), values...)
+				}
+				w.Header().Set("X-Cache", "HIT")
+				w.WriteHeader(cached.Status)
+				if r.Method != http.MethodHead {
+					w.Write(cached.Body)
+				}
+				return
+			}
+
+			w.Header().Set("X-Cache", "MISS")
+			recorder := &cacheRecorder{ResponseWriter: w}
+			next.ServeHTTP(recorder, r)
+			if !recorder.cacheable() {
+				return
+			}
+			resp := &CachedResponse{
+				Status: http.StatusOK,
+				Header: sharedHeader(w.Header()),
+				Body:   recorder.body.Bytes(),
+			}
+			vary := responseVary(w.Header())
+			if len(vary) == 0 {
+				cfg.store.Set(key, resp, ttl)
+				return
+			}
+			cfg.store.Set(key, &CachedResponse{Header: http.Header{"Vary": vary}}, ttl)
+			cfg.store.Set(cacheKey(r, append(slices.Clip(cfg.vary), vary...)), resp, ttl)
+		})
+	}
+}
+
+// cacheKey builds the cache key for a request
+func cacheKey(r *http.Request, vary []string) string {
+	var key strings.Builder
+	key.WriteString(r.Method)
+	key.WriteString(" ")
+	key.WriteString(r.URL.Path)
+	key.WriteString("?")
+	key.WriteString(r.URL.Query().Encode()) // Sorted, so parameter order doesn't matter
+	for _, name := range vary {
+		key.WriteString("\n")
+		key.WriteString(name)
+		key.WriteString(": ")
+		key.WriteString(strings.Join(r.Header.Values(name), ","))
+	}
+	return key.String()
+}
+
+// responseVary returns the sorted, canonical request header names listed in a
+// response's Vary header
+func responseVary(header http.Header) []string {
+	var names []string
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// cacheRecorder writes the response through while keeping a copy of the body
+type cacheRecorder struct {
+	http.ResponseWriter
+	status    int
+	body      bytes.Buffer
+	streaming bool
+}
+
+// WriteHeader records the status code
+func (c *cacheRecorder) WriteHeader(status int) {
+	if c.status == 0 {
+		c.status = status
+	}
+	c.ResponseWriter.WriteHeader(status)
+}
+
+// Write copies the body unless the response is streaming
+func (c *cacheRecorder) Write(b []byte) (int, error) {
+	if c.status == 0 {
+		c.status = http.StatusOK
+	}
+	if !c.streaming {
+		c.body.Write(b)
+	}
+	return c.ResponseWriter.Write(b)
+}
+
+// Flush marks the response as streaming, which is never cached
+func (c *cacheRecorder) Flush() {
+	if flusher, ok := c.ResponseWriter.(http.Flusher); ok {
+		c.streaming = true
+		c.body.Reset()
+		flusher.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (c *cacheRecorder) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
+
+// cacheable reports whether the recorded response may be stored
+func (c *cacheRecorder) cacheable() bool {
+	header := c.Header()
+	directives := cacheControlDirectives(header)
+	_, noStore := directives["no-store"]
+	private, isPrivate := directives["private"]
+	noCache, isNoCache := directives["no-cache"]
+	return (c.status == http.StatusOK || c.status == 0) &&
+		!c.streaming &&
+		!strings.HasPrefix(header.Get("Content-Type"), "text/event-stream") &&
+		len(header.Values("Set-Cookie")) == 0 &&
+		// Vary: * means the response depends on more than the request headers
+		!slices.Contains(responseVary(header), "*") &&
+		!noStore &&
+		// Unqualified private and no-cache apply to the whole response
+		!(isPrivate && private == "") &&
+		!(isNoCache && noCache == "")
+}
+
+// sharedHeader returns a copy of a response's headers without those that only apply
+// to the request that produced it: cookies, the request ID, X-Cache and the fields
+// named by Cache-Control private="..." or no-cache="..."
+func sharedHeader(header http.Header) http.Header {
+	shared := header.Clone()
+	shared.Del("X-Cache")
+	shared.Del("Set-Cookie")
+	shared.Del(RequestIDHeader)
+	directives := cacheControlDirectives(header)
+	for _, directive := range []string{"private", "no-cache"} {
+		for _, name := range strings.Split(directives[directive], ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				shared.Del(name)
+			}
+		}
+	}
+	return shared
+}
+
+// cacheControlDirectives parses the Cache-Control header into lowercase directive
+// names and their unquoted values, e.g. private="Set-Cookie, X-User"
+func cacheControlDirectives(header http.Header) map[string]string {
+	directives := make(map[string]string)
+	value := strings.Join(header.Values("Cache-Control"), ",")
+	start, quoted := 0, false
+	for i := 0; i <= len(value); i++ {
+		// Quoted values may contain commas, e.g. private="Set-Cookie, X-User"
+		if i < len(value) && value[i] == '"' {
+			quoted = !quoted
+		}
+		if i < len(value) && (value[i] != ',' || quoted) {
+			continue
+		}
+		name, arg, _ := strings.Cut(value[start:i], "=")
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			directives[name] = strings.Trim(strings.TrimSpace(arg), `"`)
+		}
+		start = i + 1
+	}
+	return directives
+}
+
+// MemoryCacheStore is an in-memory CacheStore
+type MemoryCacheStore struct {
+	entries   map[string]memoryCacheEntry
+	lastSweep int // Entry count after the last sweep of expired entries
+	mu        sync.Mutex
+}
+
+type memoryCacheEntry struct {
+	resp    *CachedResponse
+	expires time.Time
+}
+
+// NewMemoryCacheStore creates an in-memory cache store
+func NewMemoryCacheStore() *MemoryCacheStore {
+	return &MemoryCacheStore{
+		entries: make(map[string]memoryCacheEntry),
+	}
+}
+
+// Get returns the unexpired response stored under key
+func (s *MemoryCacheStore) Get(key string) (*CachedResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(s.entries, key)
+		return nil, false
+	}
+	return entry.resp, true
+}
+
+// Set stores a response under key for ttl
+func (s *MemoryCacheStore) Set(key string, resp *CachedResponse, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+
+	// Sweep expired entries whenever the store has doubled since the last sweep
+	if len(s.entries) >= 2*s.lastSweep+16 {
+		for k, entry := range s.entries {
+			if now.After(entry.expires) {
+				delete(s.entries, k)
+			}
+		}
+		s.lastSweep = len(s.entries)
+	}
+
+	s.entries[key] = memoryCacheEntry{resp: resp, expires: now.Add(ttl)}
+}
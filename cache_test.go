@@ -0,0 +1,121 @@
+package gofastapi
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type cacheMessage struct {
+	Msg string `json:"msg"`
+}
+
+func TestCacheVariesByResponseVary(t *testing.T) {
+	r := New()
+	// The cache sits outside compression, so it sees the encoded bodies
+	r.Use(CacheMiddleware(time.Minute))
+	r.EnableCompression()
+	err := r.GET("/report", func(ctx context.Context, req struct{}) (cacheMessage, error) {
+		return cacheMessage{Msg: strings.Repeat("report ", 200)}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requests := []struct {
+		name         string
+		opts         []TestOption
+		wantEncoding string
+		wantCache    string
+	}{
+		{"gzip", []TestOption{WithTestHeader("Accept-Encoding", "gzip")}, "gzip", "MISS"},
+		{"identity", nil, "", "MISS"},
+		{"gzip again", []TestOption{WithTestHeader("Accept-Encoding", "gzip")}, "gzip", "HIT"},
+		{"identity again", nil, "", "HIT"},
+	}
+	for _, tt := range requests {
+		resp, err := r.TestRequest(http.MethodGet, "/report", nil, tt.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := resp.Header.Get("Content-Encoding"); got != tt.wantEncoding {
+			t.Errorf("%s: Content-Encoding = %q, want %q", tt.name, got, tt.wantEncoding)
+		}
+		if got := resp.Header.Get("X-Cache"); got != tt.wantCache {
+			t.Errorf("%s: X-Cache = %q, want %q", tt.name, got, tt.wantCache)
+		}
+	}
+}
+
+func TestCacheSkipsCredentialedRequests(t *testing.T) {
+	handler := func(ctx context.Context, req struct {
+		User string `header:"Authorization"`
+	}) (cacheMessage, error) {
+		return cacheMessage{Msg: req.User}, nil
+	}
+	get := func(t *testing.T, r *Router, user string) (string, string) {
+		t.Helper()
+		resp, err := r.TestRequest(http.MethodGet, "/me", nil, WithTestHeader("Authorization", user))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var body cacheMessage
+		if err := resp.DecodeJSON(&body); err != nil {
+			t.Fatal(err)
+		}
+		return body.Msg, resp.Header.Get("X-Cache")
+	}
+
+	t.Run("default", func(t *testing.T) {
+		r := New()
+		if err := r.GET("/me", handler, WithMiddleware(CacheMiddleware(time.Minute))); err != nil {
+			t.Fatal(err)
+		}
+		for _, user := range []string{"alice", "bob", "alice"} {
+			if msg, cache := get(t, r, user); msg != user || cache != "" {
+				t.Errorf("%s: got %q (X-Cache %q), want their own uncached response", user, msg, cache)
+			}
+		}
+	})
+
+	t.Run("vary on Authorization", func(t *testing.T) {
+		r := New()
+		cache := CacheMiddleware(time.Minute, WithCacheVary("Authorization"))
+		if err := r.GET("/me", handler, WithMiddleware(cache)); err != nil {
+			t.Fatal(err)
+		}
+		want := []struct{ user, cache string }{{"alice", "MISS"}, {"bob", "MISS"}, {"alice", "HIT"}}
+		for _, w := range want {
+			if msg, cache := get(t, r, w.user); msg != w.user || cache != w.cache {
+				t.Errorf("%s: got %q (X-Cache %q), want %q (%s)", w.user, msg, cache, w.user, w.cache)
+			}
+		}
+	})
+}
+
+func TestCacheSkipsVaryStar(t *testing.T) {
+	r := New()
+	vary := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("Vary", "*")
+			next.ServeHTTP(w, req)
+		})
+	}
+	err := r.GET("/random", func(ctx context.Context, req struct{}) (string, error) {
+		return "ok", nil
+	}, WithMiddleware(CacheMiddleware(time.Minute), vary))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		resp, err := r.TestRequest(http.MethodGet, "/random", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := resp.Header.Get("X-Cache"); got != "MISS" {
+			t.Errorf("request %d: X-Cache = %q, want MISS", i+1, got)
+		}
+	}
+}
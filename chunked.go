@@ -0,0 +1,139 @@
+package gofastapi
+
+import (
+	"log/slog"
+	"net/http"
+	"reflect"
+)
+
+// Stream is a response type for handlers that write their body in chunks at their
+// own pace, e.g. a log tail. Extraction, validation and dependencies run before the
+// handler as usual; the body is written once the handler returns the Stream:
+//
+//	func TailLogs(ctx context.Context, req TailRequest) (*gofastapi.Stream, error) {
+//	    return gofastapi.NewStream("text/plain", func(w *gofastapi.StreamWriter) error {
+//	        for line := range tail(ctx, req.File) {
+//	            fmt.Fprintln(w, line)
+//	            if err := w.Flush(); err != nil {
+//	                return err // Client went away
+//	            }
+//	        }
+//	        return nil
+//	    }), nil
+//	}
+//
+// Responses are sent with chunked transfer encoding (HTTP/1.1) since their length
+// is unknown. An error returned before anything is written goes to the error
+// handler; after that the status is already sent, so the response is cut short.
+type Stream struct {
+	ContentType string                    // Media type (default: application/octet-stream)
+	Write       func(*StreamWriter) error // Writes the body
+}
+
+// NewStream creates a streamed response
+func NewStream(contentType string, write func(*StreamWriter) error) *Stream {
+	return &Stream{ContentType: contentType, Write: write}
+}
+
+// StreamWriter writes chunks of a streamed response
+type StreamWriter struct {
+	w       http.ResponseWriter
+	rc      *http.ResponseController
+	written bool
+}
+
+// Write writes a chunk, which may be buffered until Flush
+func (s *StreamWriter) Write(p []byte) (int, error) {
+	s.written = true
+	return s.w.Write(p)
+}
+
+// Flush sends buffered chunks to the client. It works through middleware that wraps
+// the ResponseWriter as long as the wrapper implements Flush or Unwrap.
+func (s *StreamWriter) Flush() error {
+	s.written = true
+	return s.rc.Flush()
+}
+
+var streamType = reflect.TypeOf(Stream{})
+
+// isStreamType reports whether t is Stream or *Stream
+func isStreamType(t reflect.Type) bool {
+	return t == streamType || (t.Kind() == reflect.Ptr && t.Elem() == streamType)
+}
+
+// serveStream writes a Stream response
+func serveStream(w http.ResponseWriter, r *http.Request, resp interface{}, errorHandler ErrorHandler, logger *slog.Logger) {
+	var stream *Stream
+	switch v := resp.(type) {
+	case Stream:
+		stream = &v
+	case *Stream:
+		stream = v
+	}
+	if stream == nil || stream.Write == nil {
+		errorHandler(w, r, NewError(http.StatusInternalServerError, "stream has no writer"))
+		return
+	}
+
+	contentType := stream.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	// The status and headers are only sent with the first chunk, so an error
+	// returned before any output can still become an error response
+	dw := &deferredHeaderWriter{ResponseWriter: w, contentType: contentType}
+	writer := &StreamWriter{w: dw, rc: http.NewResponseController(dw)}
+	if err := stream.Write(writer); err != nil {
+		if !writer.written {
+			errorHandler(w, r, err)
+			return
+		}
+		logger.Warn("stream ended with error", requestAttrs(r, "error", err)...)
+		return
+	}
+	if !writer.written {
+		writer.w.WriteHeader(http.StatusOK)
+	}
+}
+
+// deferredHeaderWriter sets the stream's headers and status on its first write or flush
+type deferredHeaderWriter struct {
+	http.ResponseWriter
+	contentType string
+	started     bool
+}
+
+func (d *deferredHeaderWriter) WriteHeader(status int) {
+	if d.started {
+		return
+	}
+	d.started = true
+	h := d.Header()
+	h.Set("Content-Type", d.contentType)
+	h.Del("Content-Length")
+	d.ResponseWriter.WriteHeader(status)
+}
+
+func (d *deferredHeaderWriter) Write(p []byte) (int, error) {
+	d.WriteHeader(http.StatusOK)
+	return d.ResponseWriter.Write(p)
+}
+
+// Flush starts the response if needed and flushes it
+func (d *deferredHeaderWriter) Flush() {
+	d.WriteHeader(http.StatusOK)
+	http.NewResponseController(d.ResponseWriter).Flush()
+}
+
+// FlushError is used by http.ResponseController to report flush failures
+func (d *deferredHeaderWriter) FlushError() error {
+	d.WriteHeader(http.StatusOK)
+	return http.NewResponseController(d.ResponseWriter).Flush()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (d *deferredHeaderWriter) Unwrap() http.ResponseWriter {
+	return d.ResponseWriter
+}
@@ -0,0 +1,173 @@
+package gofastapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DependencyOption configures a dependency registered with RegisterDependency.
+// Security scheme types are dependency options too.
+type DependencyOption interface {
+	applyDependency(*dependencyConfig)
+}
+
+type dependencyConfig struct {
+	schemeTypes []SecuritySchemeType
+	timeout     time.Duration
+	breaker     *circuitBreaker
+	err         error // First invalid option, reported by RegisterDependency
+}
+
+func newDependencyConfig(opts []DependencyOption) (*dependencyConfig, error) {
+	cfg := &dependencyConfig{}
+	for _, opt := range opts {
+		opt.applyDependency(cfg)
+	}
+	return cfg, cfg.err
+}
+
+// fail records the first invalid option
+func (cfg *dependencyConfig) fail(err error) {
+	if cfg.err == nil {
+		cfg.err = err
+	}
+}
+
+// applyDependency documents that the dependency authenticates with the scheme
+func (t SecuritySchemeType) applyDependency(cfg *dependencyConfig) {
+	cfg.schemeTypes = append(cfg.schemeTypes, t)
+}
+
+type dependencyOptionFunc func(*dependencyConfig)
+
+func (f dependencyOptionFunc) applyDependency(cfg *dependencyConfig) {
+	f(cfg)
+}
+
+// WithDependencyTimeout limits how long the dependency's Handle method may run. The
+// context passed to Handle is cancelled after timeout, and the request fails with
+// 504 DEPENDENCY_TIMEOUT, even if Handle ignores its context: the call runs on its own
+// goroutine, which is abandoned at the timeout and lingers until Handle returns. Zero
+// means no limit; a negative timeout fails registration.
+func WithDependencyTimeout(timeout time.Duration) DependencyOption {
+	return dependencyOptionFunc(func(cfg *dependencyConfig) {
+		if timeout < 0 {
+			cfg.fail(fmt.Errorf("dependency timeout must not be negative, got %v", timeout))
+			return
+		}
+		cfg.timeout = timeout
+	})
+}
+
+// WithCircuitBreaker makes the dependency fail fast after failures consecutive
+// failures: for cooldown, requests using it fail with 503 DEPENDENCY_UNAVAILABLE
+// and a Retry-After header without calling Handle. After the cooldown, a single
+// trial call is let through; its success closes the breaker and its failure opens
+// it for another cooldown.
+//
+// Timeouts, errors with a 5xx status and errors without a status count as
+// failures. Client errors such as a 401 for a bad token don't. failures and cooldown
+// must be positive, or registration fails.
+func WithCircuitBreaker(failures int, cooldown time.Duration) DependencyOption {
+	return dependencyOptionFunc(func(cfg *dependencyConfig) {
+		if failures <= 0 {
+			cfg.fail(fmt.Errorf("circuit breaker failure threshold must be positive, got %d", failures))
+			return
+		}
+		if cooldown <= 0 {
+			cfg.fail(fmt.Errorf("circuit breaker cooldown must be positive, got %v", cooldown))
+			return
+		}
+		cfg.breaker = &circuitBreaker{threshold: failures, cooldown: cooldown}
+	})
+}
+
+// circuitBreaker tracks consecutive failures of a dependency
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	probing   bool // A trial call is in flight after the cooldown
+	mu        sync.Mutex
+}
+
+// allow reports whether a call may proceed and, if not, how long until the next
+// trial call
+func (b *circuitBreaker) allow() (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return true, 0
+	}
+	if wait := time.Until(b.openUntil); wait > 0 {
+		return false, wait
+	}
+	if b.probing {
+		return false, 0
+	}
+	b.probing = true
+	return true, 0
+}
+
+// callOutcome is how a call let through by a circuit breaker ended
+type callOutcome int
+
+const (
+	callSucceeded callOutcome = iota
+	callFailed
+	callCanceled // The caller gave up, which says nothing about the dependency
+)
+
+// record records the outcome of a call let through by allow. A canceled call only
+// releases the trial slot, leaving the breaker's state unchanged.
+func (b *circuitBreaker) record(outcome callOutcome) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	switch outcome {
+	case callCanceled:
+		return
+	case callSucceeded:
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
+
+// unavailableError is the error for a call rejected by an open breaker
+func (b *circuitBreaker) unavailableError(name string, wait time.Duration) *Error {
+	return NewErrorWithCode(http.StatusServiceUnavailable, "DEPENDENCY_UNAVAILABLE",
+		fmt.Sprintf("Dependency %s is unavailable", name)).
+		WithHeader("Retry-After", strconv.Itoa(max(1, int(math.Ceil(wait.Seconds())))))
+}
+
+// dependencyOutcome classifies a dependency's error for its circuit breaker
+func dependencyOutcome(err error) callOutcome {
+	if err == nil {
+		return callSucceeded
+	}
+	var apiErr *Error
+	var validationErr *ValidationError
+	switch {
+	case errors.As(err, &apiErr):
+		if apiErr.Status >= http.StatusInternalServerError {
+			return callFailed
+		}
+		return callSucceeded
+	case errors.As(err, &validationErr):
+		return callSucceeded
+	case errors.Is(err, context.Canceled):
+		return callCanceled
+	}
+	return callFailed
+}
@@ -0,0 +1,187 @@
+package gofastapi
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Encoder returns a writer compressing into w with a content coding, e.g. gzip. If the
+// writer has a Flush() error method, flushing the response flushes it first, so SSE
+// events and streamed chunks reach the client compressed but undelayed.
+type Encoder func(w io.Writer) io.WriteCloser
+
+// CompressionOption configures CompressionMiddleware
+type CompressionOption func(*compressionConfig)
+
+type compressionConfig struct {
+	codings  []string // Content codings in order of preference
+	encoders map[string]Encoder
+}
+
+// WithEncoder adds a content coding, preferred over gzip and deflate and earlier
+// added codings when the client accepts them with equal q-values. For Brotli, use
+// WithEncoder from the compress/brotli module.
+func WithEncoder(coding string, encoder Encoder) CompressionOption {
+	return func(c *compressionConfig) {
+		coding = strings.ToLower(coding)
+		if _, exists := c.encoders[coding]; !exists {
+			c.codings = append([]string{coding}, c.codings...)
+		}
+		c.encoders[coding] = encoder
+	}
+}
+
+// CompressionMiddleware compresses responses with the content coding the client
+// prefers in Accept-Encoding, by q-value: gzip, deflate or one added with
+// WithEncoder. Responses that already have a Content-Encoding, partial content and
+// responses without a body are sent as is.
+func CompressionMiddleware(opts ...CompressionOption) mux.MiddlewareFunc {
+	cfg := &compressionConfig{
+		codings: []string{"gzip", "deflate"},
+		encoders: map[string]Encoder{
+			"gzip": func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+			"deflate": func(w io.Writer) io.WriteCloser {
+				fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+				return fw
+			},
+		},
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			coding := negotiateEncoding(r.Header.Get("Accept-Encoding"), cfg.codings)
+			if coding == "" || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{ResponseWriter: w, coding: coding, encoder: cfg.encoders[coding]}
+			defer cw.close()
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// EnableCompression installs CompressionMiddleware for all routes
+func (r *Router) EnableCompression(opts ...CompressionOption) {
+	r.Use(CompressionMiddleware(opts...))
+}
+
+// negotiateEncoding picks the coding with the highest q-value in accept, preferring
+// earlier codings on ties, or "" if none is acceptable
+func negotiateEncoding(accept string, codings []string) string {
+	if accept == "" {
+		return ""
+	}
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(accept, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if name == "x-gzip" {
+			name = "gzip"
+		}
+		qualities[name] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, coding := range codings {
+		q, ok := qualities[coding]
+		if !ok {
+			q, ok = qualities["*"]
+		}
+		if ok && q > bestQ {
+			best, bestQ = coding, q
+		}
+	}
+	return best
+}
+
+// compressWriter compresses the response body unless the handler's headers rule it out
+type compressWriter struct {
+	http.ResponseWriter
+	coding      string
+	encoder     Encoder
+	writer      io.WriteCloser // Compressing writer; nil until decided or when not compressing
+	wroteHeader bool
+}
+
+// WriteHeader decides whether to compress from the final headers
+func (c *compressWriter) WriteHeader(status int) {
+	if status < http.StatusOK {
+		c.ResponseWriter.WriteHeader(status) // Informational, e.g. 103 Early Hints
+		return
+	}
+	if c.wroteHeader {
+		return
+	}
+	c.wroteHeader = true
+	c.decide(status)
+	c.ResponseWriter.WriteHeader(status)
+}
+
+// Write compresses b when compressing, or passes it through
+func (c *compressWriter) Write(b []byte) (int, error) {
+	if !c.wroteHeader {
+		c.WriteHeader(http.StatusOK)
+	}
+	if c.writer == nil {
+		return c.ResponseWriter.Write(b)
+	}
+	return c.writer.Write(b)
+}
+
+// decide starts compressing unless the response has no body or is already encoded
+func (c *compressWriter) decide(status int) {
+	header := c.Header()
+	if status == http.StatusNoContent || status == http.StatusNotModified ||
+		header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return
+	}
+	header.Set("Content-Encoding", c.coding)
+	header.Del("Content-Length")
+	header.Del("Accept-Ranges") // Ranges would address the uncompressed body
+	c.writer = c.encoder(c.ResponseWriter)
+}
+
+// Flush flushes compressed data buffered by the encoder, then the response
+func (c *compressWriter) Flush() {
+	if !c.wroteHeader {
+		c.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := c.writer.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	if flusher, ok := c.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (c *compressWriter) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
+
+// close finishes the compressed stream
+func (c *compressWriter) close() {
+	if c.writer != nil {
+		c.writer.Close()
+	}
+}
@@ -0,0 +1,28 @@
+// Package brotli adds Brotli ("br") response compression to gofastapi:
+//
+//	r.EnableCompression(brotli.WithEncoder())
+//
+// It is a separate module so that only applications using Brotli depend on
+// github.com/andybalholm/brotli; the core module stays dependency-light.
+package brotli
+
+import (
+	"io"
+
+	"github.com/andybalholm/brotli"
+	"github.com/priyanshu-shubham/gofastapi"
+)
+
+// WithEncoder adds Brotli at the default compression level, preferred over gzip and
+// deflate when the client accepts them equally
+func WithEncoder() gofastapi.CompressionOption {
+	return WithEncoderLevel(brotli.DefaultCompression)
+}
+
+// WithEncoderLevel adds Brotli at a compression level from 0 (fastest) to 11
+func WithEncoderLevel(level int) gofastapi.CompressionOption {
+	return gofastapi.WithEncoder("br", func(w io.Writer) io.WriteCloser {
+		// *brotli.Writer has Flush() error, so flushed SSE events aren't delayed
+		return brotli.NewWriterLevel(w, level)
+	})
+}
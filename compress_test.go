@@ -0,0 +1,100 @@
+package gofastapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// upperWriter is a toy content coding that upper-cases the body
+type upperWriter struct {
+	w io.Writer
+}
+
+func (u upperWriter) Write(b []byte) (int, error) {
+	return u.w.Write(bytes.ToUpper(b))
+}
+
+func (u upperWriter) Close() error {
+	return nil
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	codings := []string{"br", "gzip", "deflate"}
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"", ""},
+		{"gzip, deflate, br", "br"},
+		{"gzip, br;q=0.5", "gzip"},
+		{"x-gzip", "gzip"},
+		{"*", "br"},
+		{"*;q=0, deflate", "deflate"},
+		{"br;q=0", ""},
+		{"identity", ""},
+	}
+	for _, tt := range tests {
+		if got := negotiateEncoding(tt.accept, codings); got != tt.want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", tt.accept, got, tt.want)
+		}
+	}
+}
+
+func TestCompressionWithEncoder(t *testing.T) {
+	r := New()
+	r.EnableCompression(WithEncoder("x-upper", func(w io.Writer) io.WriteCloser { return upperWriter{w} }))
+	err := r.GET("/greeting", func(ctx context.Context, req struct{}) (string, error) {
+		return "hello", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.DELETE("/greeting", func(ctx context.Context, req struct{}) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(method, accept string) *TestResponse {
+		t.Helper()
+		resp, err := r.TestRequest(method, "/greeting", nil, WithTestHeader("Accept-Encoding", accept))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if vary := resp.Header.Get("Vary"); vary != "Accept-Encoding" {
+			t.Errorf("%s %q: Vary = %q, want Accept-Encoding", method, accept, vary)
+		}
+		return resp
+	}
+
+	// Added codings win ties with the built-in ones
+	resp := get(http.MethodGet, "gzip, x-upper")
+	if got := resp.Header.Get("Content-Encoding"); got != "x-upper" {
+		t.Errorf("Content-Encoding = %q, want x-upper", got)
+	}
+	if body := strings.TrimSpace(string(resp.Body)); body != `"HELLO"` {
+		t.Errorf("body = %s, want the x-upper encoded response", body)
+	}
+
+	resp = get(http.MethodGet, "gzip, x-upper;q=0.5")
+	if got := resp.Header.Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", got)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(resp.Body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, err := io.ReadAll(zr); err != nil || strings.TrimSpace(string(body)) != `"hello"` {
+		t.Errorf("decompressed body = %q (%v), want \"hello\"", body, err)
+	}
+
+	if resp := get(http.MethodDelete, "gzip"); resp.Header.Get("Content-Encoding") != "" {
+		t.Errorf("204 response has Content-Encoding %q, want none", resp.Header.Get("Content-Encoding"))
+	}
+}
@@ -0,0 +1,82 @@
+package gofastapi
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ETager is implemented by responses that carry an entity tag. GET and HEAD
+// handlers returning one respond 304 Not Modified when If-None-Match matches.
+type ETager interface {
+	ETag() string
+}
+
+// LastModifier is implemented by responses that know when they last changed. GET and
+// HEAD handlers returning one respond 304 Not Modified when If-Modified-Since is not older.
+type LastModifier interface {
+	LastModified() time.Time
+}
+
+var (
+	etagerType       = reflect.TypeOf((*ETager)(nil)).Elem()
+	lastModifierType = reflect.TypeOf((*LastModifier)(nil)).Elem()
+)
+
+// supportsConditional reports whether responses of type t can be validated by the client
+func supportsConditional(t reflect.Type) bool {
+	return t.Implements(etagerType) || t.Implements(lastModifierType)
+}
+
+// writeConditionalHeaders sets ETag and Last-Modified from the response and reports
+// whether the request's preconditions show the client copy is still fresh
+func writeConditionalHeaders(w http.ResponseWriter, r *http.Request, resp interface{}) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	etag := ""
+	if e, ok := resp.(ETager); ok {
+		if etag = quoteETag(e.ETag()); etag != "" {
+			w.Header().Set("ETag", etag)
+		}
+	}
+
+	var modified time.Time
+	if m, ok := resp.(LastModifier); ok {
+		if modified = m.LastModified(); !modified.IsZero() {
+			w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+		}
+	}
+
+	// If-None-Match takes precedence over If-Modified-Since (RFC 9110 13.2.2)
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etag != "" && etagMatches(inm, etag)
+	}
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !modified.IsZero() {
+		since, err := http.ParseTime(ims)
+		return err == nil && !modified.Truncate(time.Second).After(since)
+	}
+	return false
+}
+
+// quoteETag wraps a bare entity tag in quotes, leaving strong and weak tags untouched
+func quoteETag(etag string) string {
+	if etag == "" || strings.HasPrefix(etag, `"`) || strings.HasPrefix(etag, `W/"`) {
+		return etag
+	}
+	return `"` + etag + `"`
+}
+
+// etagMatches performs the weak comparison used for If-None-Match
+func etagMatches(header, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+package gofastapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// Controller is optionally implemented by controllers passed to RegisterController to
+// map handler methods to routes explicitly. Routes maps method names to a verb and a
+// path relative to the controller's prefix, e.g. "GetPost": "GET /{post_id}".
+type Controller interface {
+	Routes() map[string]string
+}
+
+// controllerVerbs are the method name prefixes that derive a route's verb
+var controllerVerbs = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// RegisterController registers the exported methods of controller that have a handler
+// signature, func(context.Context, Req) (Resp, error) or func(context.Context, Req) error,
+// as routes under prefix. The methods share the controller's state, e.g. a database handle.
+//
+// Methods listed by the controller's Routes method use the route given there. Other
+// handler methods must be named after their verb: the rest of the name becomes a
+// kebab-case path segment and the request struct's path parameters are appended in
+// field order, so GetUserPosts with a path:"user_id" field becomes
+// GET {prefix}/user-posts/{user_id} and a method named Post becomes POST {prefix}.
+// opts apply to every route of the controller.
+func (r *Router) RegisterController(prefix string, controller interface{}, opts ...RouteOption) error {
+	return registerController(r, r.tagMapping(), prefix, controller, opts)
+}
+
+// RegisterController registers a controller's handler methods under the group's prefix
+func (sr *SubRouter) RegisterController(prefix string, controller interface{}, opts ...RouteOption) error {
+	return registerController(sr, sr.router.tagMapping(), prefix, controller, opts)
+}
+
+// registerController registers the handler methods of controller with reg
+func registerController(reg RouteRegistrar, tags tagMapping, prefix string, controller interface{}, opts []RouteOption) error {
+	value := reflect.ValueOf(controller)
+	if !value.IsValid() {
+		return fmt.Errorf("controller must not be nil")
+	}
+	controllerType := value.Type()
+	prefix = strings.TrimSuffix(prefix, "/")
+
+	var explicit map[string]string
+	if c, ok := controller.(Controller); ok {
+		explicit = c.Routes()
+		for name := range explicit {
+			if _, exists := controllerType.MethodByName(name); !exists {
+				return fmt.Errorf("controller %v has no method %s for route %q", controllerType, name, explicit[name])
+			}
+		}
+	}
+
+	for i := 0; i < controllerType.NumMethod(); i++ {
+		method := controllerType.Method(i)
+		if !isHandlerSignature(method.Type.In(0), method.Type) {
+			if _, listed := explicit[method.Name]; listed {
+				return fmt.Errorf("controller method %s does not have a handler signature", method.Name)
+			}
+			continue
+		}
+
+		var verb, path string
+		if route, listed := explicit[method.Name]; listed {
+			var ok bool
+			verb, path, ok = strings.Cut(strings.TrimSpace(route), " ")
+			if !ok {
+				return fmt.Errorf("controller method %s: route %q must be a verb and a path, e.g. \"GET /{id}\"", method.Name, route)
+			}
+			verb, path = strings.ToUpper(verb), strings.TrimSpace(path)
+		} else {
+			var ok bool
+			verb, path, ok = controllerRoute(method, tags)
+			if !ok {
+				return fmt.Errorf("controller method %s has a handler signature but no route; name it after its verb, e.g. Get%s, or list it in Routes",
+					method.Name, method.Name)
+			}
+		}
+
+		fullPath := prefix + strings.TrimSuffix(path, "/")
+		if fullPath == "" {
+			fullPath = "/"
+		}
+		handler := value.Method(i).Interface()
+		var err error
+		switch verb {
+		case http.MethodGet:
+			err = reg.GET(fullPath, handler, opts...)
+		case http.MethodPost:
+			err = reg.POST(fullPath, handler, opts...)
+		case http.MethodPut:
+			err = reg.PUT(fullPath, handler, opts...)
+		case http.MethodPatch:
+			err = reg.PATCH(fullPath, handler, opts...)
+		case http.MethodDelete:
+			err = reg.DELETE(fullPath, handler, opts...)
+		default:
+			err = fmt.Errorf("unsupported verb %q", verb)
+		}
+		if err != nil {
+			return fmt.Errorf("controller method %s: %w", method.Name, err)
+		}
+	}
+	return nil
+}
+
+// isHandlerSignature reports whether methodType, a method of receiver, takes a
+// context and a request struct and returns an error, optionally after a response
+func isHandlerSignature(receiver, methodType reflect.Type) bool {
+	// Method types from reflect.Type include the receiver as the first input
+	if methodType.NumIn() != 3 || methodType.In(0) != receiver {
+		return false
+	}
+	if methodType.In(1) != reflect.TypeOf((*context.Context)(nil)).Elem() || methodType.In(2).Kind() != reflect.Struct {
+		return false
+	}
+	if methodType.NumOut() < 1 || methodType.NumOut() > 2 {
+		return false
+	}
+	return methodType.Out(methodType.NumOut()-1) == reflect.TypeOf((*error)(nil)).Elem()
+}
+
+// controllerRoute derives a route from a handler method's name and path parameters
+func controllerRoute(method reflect.Method, tags tagMapping) (verb, path string, ok bool) {
+	for _, candidate := range controllerVerbs {
+		name := method.Name
+		prefix := candidate[:1] + strings.ToLower(candidate[1:]) // e.g. Get
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(name, prefix)
+		if rest != "" && (rest[0] < 'A' || rest[0] > 'Z') {
+			continue // e.g. Getaway
+		}
+
+		if rest != "" {
+			path = "/" + strings.ReplaceAll(NamingPolicySnakeCase.Apply(rest), "_", "-")
+		}
+		reqType := method.Type.In(2)
+		for i := 0; i < reqType.NumField(); i++ {
+			if param := tags.tagFor(reqType.Field(i), "path"); param != "" {
+				path += "/{" + param + "}"
+			}
+		}
+		return candidate, path, true
+	}
+	return "", "", false
+}
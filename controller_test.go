@@ -0,0 +1,85 @@
+package gofastapi
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type userController struct {
+	greeting string // Shared state, e.g. a database handle
+}
+
+func (c *userController) Routes() map[string]string {
+	return map[string]string{"Archive": "PUT /{id}/archive"}
+}
+
+func (c *userController) GetUserPosts(ctx context.Context, req struct {
+	UserID string `path:"user_id"`
+}) (string, error) {
+	return c.greeting + " posts of " + req.UserID, nil
+}
+
+func (c *userController) Post(ctx context.Context, req struct{}) error {
+	return nil
+}
+
+func (c *userController) Archive(ctx context.Context, req struct {
+	ID string `path:"id"`
+}) (string, error) {
+	return "archived " + req.ID, nil
+}
+
+// Name has no handler signature, so it is not a route
+func (c *userController) Name() string {
+	return "users"
+}
+
+type unroutedController struct{}
+
+func (unroutedController) Search(ctx context.Context, req struct{}) (string, error) {
+	return "", nil
+}
+
+func TestRegisterController(t *testing.T) {
+	r := New()
+	if err := r.Group("/api").RegisterController("/users", &userController{greeting: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		method, path string
+		wantStatus   int
+		want         string
+	}{
+		{http.MethodGet, "/api/users/user-posts/42", http.StatusOK, "hello posts of 42"},
+		{http.MethodPost, "/api/users", http.StatusNoContent, ""},
+		{http.MethodPut, "/api/users/7/archive", http.StatusOK, "archived 7"},
+	}
+	for _, tt := range tests {
+		resp, err := r.TestRequest(tt.method, tt.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("%s %s: status = %d, want %d", tt.method, tt.path, resp.StatusCode, tt.wantStatus)
+			continue
+		}
+		if tt.want == "" {
+			continue
+		}
+		var got string
+		if err := resp.DecodeJSON(&got); err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("%s %s = %q, want %q", tt.method, tt.path, got, tt.want)
+		}
+	}
+
+	err := New().RegisterController("/search", unroutedController{})
+	if err == nil || !strings.Contains(err.Error(), "Search") {
+		t.Errorf("RegisterController error = %v, want one naming the unrouted Search method", err)
+	}
+}
@@ -0,0 +1,115 @@
+package gofastapi
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// defaultMaxDecodedBodySize bounds gzip and deflate request bodies after decompression
+// when no max body size is set, so a small upload can't expand into gigabytes
+const defaultMaxDecodedBodySize = 10 << 20
+
+// readBody reads the request body, decompressing it according to Content-Encoding
+// (gzip or deflate). Bodies larger than maxSize bytes after decompression fail with
+// 413; a maxSize of 0 only limits decompressed bodies, to defaultMaxDecodedBodySize.
+func readBody(r *http.Request, maxSize int64) ([]byte, error) {
+	reader, err := bodyReader(r, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// bodyReader returns the request body decompressed and limited like readBody, for
+// callers that decode it incrementally
+func bodyReader(r *http.Request, maxSize int64) (io.ReadCloser, error) {
+	encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+	reader, err := decodeBody(r.Body, encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	compressed := encoding != "" && encoding != "identity"
+	if maxSize <= 0 && compressed {
+		maxSize = defaultMaxDecodedBodySize
+	}
+	return &limitedBody{body: reader, maxSize: maxSize, compressed: compressed, encoding: encoding}, nil
+}
+
+// limitedBody reads a decoded request body, failing with 413 past maxSize bytes (if
+// positive) and with 400 on corrupt compressed data
+type limitedBody struct {
+	body       io.ReadCloser
+	maxSize    int64
+	read       int64
+	compressed bool
+	encoding   string
+}
+
+// Read reads from the body, converting limit and decoding failures to API errors
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.maxSize > 0 && int64(len(p)) > b.maxSize-b.read+1 {
+		// Read at most one byte past the limit to detect oversized bodies
+		p = p[:b.maxSize-b.read+1]
+	}
+	n, err := b.body.Read(p)
+	b.read += int64(n)
+	if b.maxSize > 0 && b.read > b.maxSize {
+		return n - int(b.read-b.maxSize), NewErrorWithCode(http.StatusRequestEntityTooLarge, "REQUEST_TOO_LARGE",
+			fmt.Sprintf("Request body exceeds %d bytes", b.maxSize))
+	}
+	if err != nil && err != io.EOF {
+		if b.compressed {
+			return n, NewErrorWithCode(http.StatusBadRequest, "INVALID_CONTENT_ENCODING",
+				fmt.Sprintf("Request body is not valid %s data", b.encoding))
+		}
+		return n, fmt.Errorf("failed to read request body: %w", err)
+	}
+	return n, err
+}
+
+// Close closes the decompressor
+func (b *limitedBody) Close() error {
+	return b.body.Close()
+}
+
+// decodeBody wraps body in a decompressor for the given content coding
+func decodeBody(body io.ReadCloser, encoding string) (io.ReadCloser, error) {
+	switch encoding {
+	case "", "identity":
+		return body, nil
+	case "gzip", "x-gzip":
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, NewErrorWithCode(http.StatusBadRequest, "INVALID_CONTENT_ENCODING", "Request body is not valid gzip data")
+		}
+		return gz, nil
+	case "deflate":
+		// HTTP's deflate is zlib-wrapped, but some clients send raw deflate data
+		buffered := bufio.NewReader(body)
+		header, _ := buffered.Peek(2)
+		if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+			zr, err := zlib.NewReader(buffered)
+			if err != nil {
+				return nil, NewErrorWithCode(http.StatusBadRequest, "INVALID_CONTENT_ENCODING", "Request body is not valid deflate data")
+			}
+			return zr, nil
+		}
+		return flate.NewReader(buffered), nil
+	default:
+		return nil, NewErrorWithCode(http.StatusUnsupportedMediaType, "UNSUPPORTED_CONTENT_ENCODING",
+			fmt.Sprintf("Content-Encoding %s is not supported", encoding))
+	}
+}
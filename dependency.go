@@ -2,11 +2,17 @@ package gofastapi
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"reflect"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Dependency represents a dependency that can be injected
@@ -17,22 +23,68 @@ type Dependency interface {
 // DependencyResolver manages dependency resolution
 type DependencyResolver struct {
 	dependencies map[string]*compiledDependency
+	config       *atomic.Pointer[routerConfig] // Settings of the router serving the requests
 	mu           sync.RWMutex
 }
 
 type compiledDependency struct {
-	instance    interface{}
-	handlerFunc reflect.Value
-	reqType     reflect.Type
-	respType    reflect.Type
-	extractors  map[int]FieldExtractor
-	validators  map[int]string
+	instance     interface{}
+	handlerFunc  reflect.Value
+	reqType      reflect.Type
+	respType     reflect.Type
+	extractors   map[int]FieldExtractor
+	validators   map[int]string
+	fieldSources map[string]string
+	timeout      time.Duration   // Limit on the Handle call, if set
+	breaker      *circuitBreaker // Set with WithCircuitBreaker
 }
 
 // ResolvedDependencies holds resolved dependency values for a request
 type ResolvedDependencies struct {
-	values map[string]interface{}
-	mu     sync.Mutex
+	values  map[string]interface{}
+	order   []string                // Dependency names in resolution order
+	pending map[string]*pendingCall // Dependencies currently being resolved
+	skipped map[string]bool         // Unused alternatives of RequireAnyOf groups
+	mu      sync.Mutex
+}
+
+// isSkipped reports whether name is an unused alternative of a RequireAnyOf group
+func (rd *ResolvedDependencies) isSkipped(name string) bool {
+	rd.mu.Lock()
+	defer rd.mu.Unlock()
+	return rd.skipped[name]
+}
+
+// pendingCall is an in-flight dependency resolution that concurrent callers wait on
+type pendingCall struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+// HeaderApplier can be implemented by dependency results (or errors) to set
+// response headers, e.g. rate limit counters. Headers are applied before the
+// response is written, whether the handler succeeds or fails.
+type HeaderApplier interface {
+	ApplyHeaders(h http.Header)
+}
+
+// applyHeaders applies headers from resolved dependency results and err, in resolution order
+func (rd *ResolvedDependencies) applyHeaders(h http.Header, err error) {
+	if rd != nil {
+		rd.mu.Lock()
+		for _, name := range rd.order {
+			if applier, ok := rd.values[name].(HeaderApplier); ok {
+				applier.ApplyHeaders(h)
+			}
+		}
+		rd.mu.Unlock()
+	}
+
+	var applier HeaderApplier
+	if errors.As(err, &applier) {
+		applier.ApplyHeaders(h)
+	}
 }
 
 // DependencyError wraps errors that occur during dependency resolution
@@ -52,11 +104,38 @@ func (e *DependencyError) Unwrap() error {
 func NewDependencyResolver() *DependencyResolver {
 	return &DependencyResolver{
 		dependencies: make(map[string]*compiledDependency),
+		config:       defaultRouterConfig(),
 	}
 }
 
+// pathParams returns the path parameters read by the named registered dependencies
+func (dr *DependencyResolver) pathParams(names []string) *pathParamReads {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	reads := &pathParamReads{names: make(map[string]bool)}
+	for _, name := range names {
+		dep, ok := dr.dependencies[name]
+		if !ok {
+			continue
+		}
+		for _, extractor := range dep.extractors {
+			reads.add(extractor)
+		}
+	}
+	return reads
+}
+
 // Register compiles and registers a dependency
-func (dr *DependencyResolver) Register(name string, dep interface{}) error {
+func (dr *DependencyResolver) Register(name string, dep interface{}, opts ...DependencyOption) error {
+	cfg, err := newDependencyConfig(opts)
+	if err != nil {
+		return fmt.Errorf("dependency %s: %w", name, err)
+	}
+	return dr.register(name, dep, cfg, nil)
+}
+
+func (dr *DependencyResolver) register(name string, dep interface{}, cfg *dependencyConfig, tags tagMapping) error {
 	dr.mu.Lock()
 	defer dr.mu.Unlock()
 
@@ -88,33 +167,144 @@ func (dr *DependencyResolver) Register(name string, dep interface{}) error {
 	}
 
 	// Compile extractors for the request struct
-	extractors, validators, err := compileStructExtractors(reqType)
+	extractors, validators, err := compileStructExtractors(reqType, tags)
 	if err != nil {
 		return fmt.Errorf("failed to compile dependency extractors: %w", err)
 	}
 
 	dr.dependencies[name] = &compiledDependency{
-		instance:    dep,
-		handlerFunc: handleMethod,
-		reqType:     reqType,
-		respType:    respType,
-		extractors:  extractors,
-		validators:  validators,
+		instance:     dep,
+		handlerFunc:  handleMethod,
+		reqType:      reqType,
+		respType:     respType,
+		extractors:   extractors,
+		validators:   validators,
+		fieldSources: compileFieldSources(reqType, extractors),
+		timeout:      cfg.timeout,
+		breaker:      cfg.breaker,
+	}
+
+	return nil
+}
+
+// dependencyClosure returns the sorted names of the given dependencies and, transitively,
+// the dependencies they rely on
+func (dr *DependencyResolver) dependencyClosure(direct map[int]string, groups [][]string) []string {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var visit func(name string)
+	visit = func(name string) {
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		if dep, ok := dr.dependencies[name]; ok {
+			for _, extractor := range dep.extractors {
+				if depExt, ok := extractor.(*DependencyExtractor); ok {
+					visit(depExt.depName)
+				}
+			}
+		}
 	}
+	for _, name := range direct {
+		visit(name)
+	}
+	for _, group := range groups {
+		for _, name := range group {
+			visit(name)
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
 
+// checkAnyOf reports RequireAnyOf groups that are empty or name dependencies that
+// aren't registered, which would otherwise only fail once a request arrives
+func (dr *DependencyResolver) checkAnyOf(groups [][]string) error {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	for _, group := range groups {
+		if len(group) == 0 {
+			return fmt.Errorf("RequireAnyOf needs at least one dependency")
+		}
+		for _, name := range group {
+			if _, ok := dr.dependencies[name]; !ok {
+				return fmt.Errorf("RequireAnyOf dependency %q is not registered", name)
+			}
+		}
+	}
 	return nil
 }
 
-// Resolve executes a dependency and caches the result
+// Resolve executes a dependency and caches the result. Each dependency runs at most
+// once per request: concurrent callers wait for the in-flight resolution and share its
+// result. Errors from the dependency, or from any dependency it relies on, are
+// returned unwrapped so their type and status reach the error handler intact.
 func (dr *DependencyResolver) Resolve(ctx context.Context, name string, r *http.Request, vars map[string]string, body []byte, resolved *ResolvedDependencies) (interface{}, error) {
-	// Check if already resolved
+	return dr.resolve(ctx, name, r, vars, body, resolved, nil)
+}
+
+// resolve resolves name once per request; chain holds the dependencies whose
+// resolution led here, to report cycles instead of waiting on them forever
+func (dr *DependencyResolver) resolve(ctx context.Context, name string, r *http.Request, vars map[string]string, body []byte, resolved *ResolvedDependencies, chain []string) (interface{}, error) {
+	if slices.Contains(chain, name) {
+		return nil, fmt.Errorf("dependency cycle: %s -> %s", strings.Join(chain, " -> "), name)
+	}
+
 	resolved.mu.Lock()
 	if val, ok := resolved.values[name]; ok {
 		resolved.mu.Unlock()
 		return val, nil
 	}
+	if call, ok := resolved.pending[name]; ok {
+		resolved.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.value, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	call := &pendingCall{done: make(chan struct{})}
+	if resolved.pending == nil {
+		resolved.pending = make(map[string]*pendingCall)
+	}
+	resolved.pending[name] = call
 	resolved.mu.Unlock()
 
+	// Publish the outcome even if the dependency panics, so waiters never hang
+	completed := false
+	defer func() {
+		resolved.mu.Lock()
+		if !completed {
+			call.err = fmt.Errorf("dependency %s panicked", name)
+		} else if call.err == nil {
+			if resolved.values == nil {
+				resolved.values = make(map[string]interface{})
+			}
+			resolved.values[name] = call.value
+			resolved.order = append(resolved.order, name)
+		}
+		delete(resolved.pending, name)
+		resolved.mu.Unlock()
+		close(call.done)
+	}()
+
+	call.value, call.err = dr.execute(ctx, name, r, vars, body, resolved, append(slices.Clip(chain), name))
+	completed = true
+	return call.value, call.err
+}
+
+// execute extracts a dependency's request, validates it and calls its Handle method
+func (dr *DependencyResolver) execute(ctx context.Context, name string, r *http.Request, vars map[string]string, body []byte, resolved *ResolvedDependencies, chain []string) (interface{}, error) {
 	dr.mu.RLock()
 	dep, ok := dr.dependencies[name]
 	dr.mu.RUnlock()
@@ -133,8 +323,16 @@ func (dr *DependencyResolver) Resolve(ctx context.Context, name string, r *http.
 
 		// Handle dependency extractors specially
 		if depExt, ok := extractor.(*DependencyExtractor); ok {
+			if resolved.isSkipped(depExt.depName) {
+				continue
+			}
+			if depExt.lazy {
+				reqValue.Field(fieldIdx).Set(dr.lazyDependency(ctx, depExt, r, vars, body, resolved, chain))
+				continue
+			}
+
 			// Resolve the dependency first
-			depResult, err := dr.Resolve(ctx, depExt.depName, r, vars, body, resolved)
+			depResult, err := dr.resolve(ctx, depExt.depName, r, vars, body, resolved, chain)
 			if err != nil {
 				// Propagate the error as-is if it's already a known error type
 				return nil, err
@@ -146,6 +344,11 @@ func (dr *DependencyResolver) Resolve(ctx context.Context, name string, r *http.
 			} else {
 				value = depResult
 			}
+		} else if jsonExt, ok := extractor.(*JSONExtractor); ok {
+			value, err = jsonExt.extract(body, dr.config.Load().lenientArrays)
+			if err != nil {
+				return nil, err
+			}
 		} else {
 			value, err = extractor.Extract(r, vars, body)
 			if err != nil {
@@ -162,31 +365,175 @@ func (dr *DependencyResolver) Resolve(ctx context.Context, name string, r *http.
 		}
 	}
 
+	// Normalize tagged string fields before they are validated
+	if err := applyTransforms(reqValue); err != nil {
+		return nil, err
+	}
+
 	// Validate the request - this returns ValidationError which we need to preserve
-	if err := validateStruct(reqValue.Interface(), dep.validators); err != nil {
+	if err := validateStruct(reqValue.Interface(), dep.fieldSources, dr.config.Load().validationStatus, translatorFor(r)); err != nil {
 		// Don't wrap validation errors, return them as-is
 		return nil, err
 	}
 
-	// Call the dependency handler
-	results := dep.handlerFunc.Call([]reflect.Value{
-		reflect.ValueOf(ctx),
-		reqValue,
-	})
+	if dep.breaker == nil {
+		return dr.call(ctx, name, dep, r, reqValue)
+	}
+	allowed, wait := dep.breaker.allow()
+	if !allowed {
+		return nil, dep.breaker.unavailableError(name, wait)
+	}
+	// Record panics as failures too, so a trial call can't leave the breaker stuck
+	outcome := callFailed
+	defer func() { dep.breaker.record(outcome) }()
+	result, err := dr.call(ctx, name, dep, r, reqValue)
+	outcome = dependencyOutcome(err)
+	return result, err
+}
+
+// call calls the dependency handler, giving up once the request deadline or the
+// dependency's timeout passes
+func (dr *DependencyResolver) call(ctx context.Context, name string, dep *compiledDependency, r *http.Request, reqValue reflect.Value) (interface{}, error) {
+	callCtx := ctx
+	if dep.timeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, dep.timeout)
+		defer cancel()
+	}
 
-	if !results[1].IsNil() {
+	args := []reflect.Value{reflect.ValueOf(callCtx), reqValue}
+	var results []reflect.Value
+	var err error
+	if dep.timeout > 0 {
+		// Only an explicit dependency timeout abandons a Handle call that overruns it
+		results, err = callWithDeadline(callCtx, dr.config.Load().log(), r, dep.handlerFunc, args)
+	} else {
+		results = dep.handlerFunc.Call(args)
+	}
+	if err == nil && !results[1].IsNil() {
 		// Return the error from the handler as-is to preserve its type
-		return nil, results[1].Interface().(error)
+		err = results[1].Interface().(error)
+	}
+	if err != nil {
+		if ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+			return nil, NewErrorWithCode(http.StatusGatewayTimeout, "DEPENDENCY_TIMEOUT",
+				fmt.Sprintf("Dependency %s timed out", name))
+		}
+		return nil, err
 	}
 
-	result := results[0].Interface()
+	return results[0].Interface(), nil
+}
 
-	// Cache the result
-	resolved.mu.Lock()
-	resolved.values[name] = result
-	resolved.mu.Unlock()
+// resolveAnyOf resolves the dependencies of each group in turn until one succeeds.
+// The group's other dependencies are skipped: fields referencing them keep their zero
+// value. If no dependency of a group succeeds, the first one's error is returned.
+func (dr *DependencyResolver) resolveAnyOf(ctx context.Context, groups [][]string, r *http.Request, vars map[string]string, body []byte, resolved *ResolvedDependencies) error {
+	for _, group := range groups {
+		var firstErr error
+		chosen := ""
+		for _, name := range group {
+			if _, err := dr.Resolve(ctx, name, r, vars, body, resolved); err != nil {
+				dr.config.Load().log().Debug("dependency alternative failed", requestAttrs(r, "dependency", name, "error", err)...)
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			chosen = name
+			break
+		}
+		if chosen == "" {
+			return firstErr
+		}
+
+		resolved.mu.Lock()
+		for _, name := range group {
+			if _, ok := resolved.values[name]; !ok {
+				if resolved.skipped == nil {
+					resolved.skipped = make(map[string]bool)
+				}
+				resolved.skipped[name] = true
+			}
+		}
+		resolved.mu.Unlock()
+	}
+	return nil
+}
+
+// callWithDeadline calls fn on its own goroutine, returning ctx.Err() if ctx's
+// deadline passes before fn returns. fn keeps running in the background in that case
+// and its results are discarded, so a fn ignoring ctx leaks one goroutine per timed
+// out call until it returns. Panics are re-raised on the calling goroutine, or logged
+// if they happen after the deadline.
+func callWithDeadline(ctx context.Context, logger *slog.Logger, r *http.Request, fn reflect.Value, args []reflect.Value) ([]reflect.Value, error) {
+	type outcome struct {
+		results   []reflect.Value
+		recovered interface{}
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				done <- outcome{recovered: recovered}
+			}
+		}()
+		done <- outcome{results: fn.Call(args)}
+	}()
+
+	select {
+	case out := <-done:
+		if out.recovered != nil {
+			panic(out.recovered)
+		}
+		return out.results, nil
+	case <-ctx.Done():
+		go func() {
+			if out := <-done; out.recovered != nil {
+				logger.Error("panic after the request ended", requestAttrs(r, "panic", out.recovered)...)
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// isLazyDependencyType reports whether t is a func() (T, error) for a lazy dependency field
+func isLazyDependencyType(t reflect.Type) bool {
+	return t.Kind() == reflect.Func && t.NumIn() == 0 && t.NumOut() == 2 && t.Out(1) == errorType
+}
+
+// lazyDependency returns the func() (T, error) for a lazy dependency field. The first
+// call resolves the dependency; like eager fields, later calls and other references in
+// the request share that result. Unused RequireAnyOf alternatives yield the zero value.
+func (dr *DependencyResolver) lazyDependency(ctx context.Context, depExt *DependencyExtractor, r *http.Request, vars map[string]string, body []byte, resolved *ResolvedDependencies, chain []string) reflect.Value {
+	resultType := depExt.fieldType.Out(0)
+	return reflect.MakeFunc(depExt.fieldType, func([]reflect.Value) []reflect.Value {
+		result := reflect.New(resultType).Elem()
+		noError := reflect.Zero(errorType)
+		if resolved.isSkipped(depExt.depName) {
+			return []reflect.Value{result, noError}
+		}
 
-	return result, nil
+		depResult, err := dr.resolve(ctx, depExt.depName, r, vars, body, resolved, chain)
+		if err != nil {
+			return []reflect.Value{result, reflect.ValueOf(&err).Elem()}
+		}
+		value := depResult
+		if len(depExt.fieldPath) > 1 {
+			value = extractNestedField(depResult, depExt.fieldPath[1:])
+		}
+		if value != nil {
+			if v := reflect.ValueOf(value); v.Type().ConvertibleTo(resultType) {
+				result.Set(v.Convert(resultType))
+			} else {
+				err := fmt.Errorf("dependency %s returned %v, not %v", depExt.depName, v.Type(), resultType)
+				return []reflect.Value{result, reflect.ValueOf(&err).Elem()}
+			}
+		}
+		return []reflect.Value{result, noError}
+	})
 }
 
 // extractNestedField extracts a nested field from a struct
@@ -0,0 +1,270 @@
+package gofastapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRouteTimeout(t *testing.T) {
+	slow := func(ctx context.Context) error {
+		select {
+		case <-time.After(time.Second):
+			return nil
+		case <-ctx.Done():
+			// Keep running past the deadline, like code that ignores its context
+			time.Sleep(50 * time.Millisecond)
+			return nil
+		}
+	}
+
+	r := New()
+	err := RegisterDependency(r, "slow", func(ctx context.Context, req struct{}) (string, error) {
+		return "done", slow(ctx)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.GET("/slow-dependency", func(ctx context.Context, req struct {
+		Value string `dep:"slow"`
+	}) (string, error) {
+		return req.Value, nil
+	}, WithTimeout(20*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.GET("/slow-handler", func(ctx context.Context, req struct{}) (string, error) {
+		return "done", slow(ctx)
+	}, WithTimeout(20*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"/slow-dependency", "/slow-handler"} {
+		t.Run(path, func(t *testing.T) {
+			start := time.Now()
+			resp, err := r.TestRequest(http.MethodGet, path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != http.StatusGatewayTimeout {
+				t.Fatalf("status = %d, want 504; body: %s", resp.StatusCode, resp.Body)
+			}
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Errorf("request took %v, want it to end at the deadline", elapsed)
+			}
+		})
+	}
+}
+
+func TestClientClosedRequest(t *testing.T) {
+	r := New()
+	err := r.GET("/wait", func(ctx context.Context, req struct{}) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/wait", nil).WithContext(ctx))
+	if recorder.Code != StatusClientClosedRequest {
+		t.Fatalf("status = %d, want %d", recorder.Code, StatusClientClosedRequest)
+	}
+}
+
+type limitDep struct{}
+
+func (limitDep) Handle(ctx context.Context, req struct{}) (string, error) {
+	return "", NewErrorWithCode(http.StatusTooManyRequests, "RATE_LIMITED", "Too many requests").
+		WithHeader("Retry-After", "30")
+}
+
+type sessionDep struct{}
+
+func (sessionDep) Handle(ctx context.Context, req struct {
+	Limit string `dep:"limit"`
+}) (string, error) {
+	return "session", nil
+}
+
+type userDep struct{}
+
+func (userDep) Handle(ctx context.Context, req struct {
+	Session string `dep:"session"`
+}) (string, error) {
+	return "user", nil
+}
+
+func TestNestedDependencyErrorKeepsStatus(t *testing.T) {
+	r := New()
+	deps := []struct {
+		name string
+		dep  interface{}
+	}{{"limit", limitDep{}}, {"session", sessionDep{}}, {"user", userDep{}}}
+	for _, d := range deps {
+		if err := r.RegisterDependency(d.name, d.dep); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := r.GET("/me", func(ctx context.Context, req struct {
+		User string `dep:"user"`
+	}) (string, error) {
+		return req.User, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := r.TestRequest(http.MethodGet, "/me", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429; body: %s", resp.StatusCode, resp.Body)
+	}
+	if got := resp.Header.Get("Retry-After"); got != "30" {
+		t.Errorf("Retry-After = %q, want 30", got)
+	}
+	var body ErrorResponse
+	if err := resp.DecodeJSON(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Code != "RATE_LIMITED" {
+		t.Errorf("code = %q, want RATE_LIMITED", body.Code)
+	}
+}
+
+func TestResolveRunsDependencyOnceConcurrently(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	r := New()
+	err := RegisterDependency(r, "session", func(ctx context.Context, req struct{}) (string, error) {
+		calls.Add(1)
+		<-release
+		return "session", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	resolved := &ResolvedDependencies{values: make(map[string]interface{})}
+	const goroutines = 20
+	results := make(chan interface{}, goroutines)
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := r.depResolver.Resolve(context.Background(), "session", req, nil, nil, resolved)
+			if err != nil {
+				t.Error(err)
+			}
+			results <- value
+		}()
+	}
+	// Let the goroutines pile up on the first call before it completes
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("dependency ran %d times, want 1", n)
+	}
+	for value := range results {
+		if value != "session" {
+			t.Errorf("Resolve = %v, want session", value)
+		}
+	}
+}
+
+func TestDependencyOptionsAreValidated(t *testing.T) {
+	dep := func(ctx context.Context, req struct{}) (string, error) {
+		return "ok", nil
+	}
+	tests := []struct {
+		name    string
+		opt     DependencyOption
+		wantErr bool
+	}{
+		{"valid breaker", WithCircuitBreaker(5, time.Second), false},
+		{"zero failures", WithCircuitBreaker(0, time.Second), true},
+		{"zero cooldown", WithCircuitBreaker(5, 0), true},
+		{"no timeout", WithDependencyTimeout(0), false},
+		{"negative timeout", WithDependencyTimeout(-time.Second), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RegisterDependency(New(), "dep", dep, tt.opt)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RegisterDependency error = %v, want error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+type stuckDep struct{}
+
+func (stuckDep) Handle(ctx context.Context, req struct{}) (string, error) {
+	time.Sleep(200 * time.Millisecond) // Ignores ctx
+	return "late", nil
+}
+
+func TestDependencyTimeoutAbandonsCall(t *testing.T) {
+	r := New()
+	if err := r.RegisterDependency("stuck", stuckDep{}, WithDependencyTimeout(10*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	err := r.GET("/stuck", func(ctx context.Context, req struct {
+		Value string `dep:"stuck"`
+	}) (string, error) {
+		return req.Value, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	resp, err := r.TestRequest(http.MethodGet, "/stuck", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("request took %v, want the dependency abandoned at its timeout", elapsed)
+	}
+	var body ErrorResponse
+	if err := resp.DecodeJSON(&body); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusGatewayTimeout || body.Code != "DEPENDENCY_TIMEOUT" {
+		t.Errorf("status = %d, code = %q, want 504 DEPENDENCY_TIMEOUT", resp.StatusCode, body.Code)
+	}
+}
+
+func TestCanceledTrialCallLeavesBreakerOpen(t *testing.T) {
+	b := &circuitBreaker{threshold: 1, cooldown: time.Millisecond}
+	b.record(callFailed)
+	time.Sleep(2 * time.Millisecond)
+
+	if allowed, _ := b.allow(); !allowed {
+		t.Fatal("trial call after the cooldown was rejected")
+	}
+	b.record(dependencyOutcome(context.Canceled))
+
+	// The breaker is still half-open: one trial call at a time
+	if allowed, _ := b.allow(); !allowed {
+		t.Fatal("trial call after a canceled one was rejected")
+	}
+	if allowed, _ := b.allow(); allowed {
+		t.Error("second concurrent call was let through, want the breaker still half-open")
+	}
+}
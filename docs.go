@@ -0,0 +1,143 @@
+package gofastapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+
+	"github.com/MarceloPetrucio/go-scalar-api-reference"
+)
+
+// DocsRenderer renders the HTML page of an API docs UI for a spec
+type DocsRenderer interface {
+	Render(spec *OpenAPISpec) ([]byte, error)
+}
+
+// ServeDocsWith serves the docs page produced by renderer at path. The page is
+// rendered per request, so routes registered later are included.
+//
+//	r.ServeDocsWith("/docs", gofastapi.SwaggerUIDocs{})
+func (r *Router) ServeDocsWith(path string, renderer DocsRenderer) {
+	r.mux.Handle(path, r.withMiddleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		page, err := renderer.Render(r.GenerateOpenAPISpec())
+		if err != nil {
+			http.Error(w, "Failed to generate API reference HTML", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(page)
+	}), nil, nil)).Methods(http.MethodGet)
+}
+
+// ScalarDocs renders docs with Scalar. Nil Options use the Kepler theme in dark mode.
+// Unless Options set SpecURL or SpecContent, the spec is embedded in the page.
+type ScalarDocs struct {
+	Options *scalar.Options
+}
+
+// Render implements DocsRenderer
+func (d ScalarDocs) Render(spec *OpenAPISpec) ([]byte, error) {
+	var options scalar.Options
+	if d.Options != nil {
+		options = *d.Options
+	} else {
+		options = scalar.Options{
+			Theme:    scalar.ThemeKepler,
+			DarkMode: true,
+		}
+	}
+	if options.CustomOptions.PageTitle == "" {
+		options.CustomOptions.PageTitle = docsTitle(spec, "")
+	}
+	if options.SpecURL == "" && options.SpecContent == nil {
+		data, err := json.Marshal(spec)
+		if err != nil {
+			return nil, err
+		}
+		options.SpecContent = string(data)
+	}
+
+	page, err := scalar.ApiReferenceHTML(&options)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(page), nil
+}
+
+// SwaggerUIDocs renders docs with Swagger UI, loaded from a CDN
+type SwaggerUIDocs struct {
+	Title string // Page title (default: the spec title followed by "Documentation")
+	CDN   string // Base URL of the swagger-ui-dist files (default: unpkg, version 5)
+}
+
+// Render implements DocsRenderer
+func (d SwaggerUIDocs) Render(spec *OpenAPISpec) ([]byte, error) {
+	cdn := d.CDN
+	if cdn == "" {
+		cdn = "https://unpkg.com/swagger-ui-dist@5"
+	}
+	data, err := json.Marshal(spec) // Escapes <, > and &, so the spec can't close the script
+	if err != nil {
+		return nil, err
+	}
+	return []byte(fmt.Sprintf(`<!DOCTYPE html>
+<html>
+  <head>
+    <title>%s</title>
+    <meta charset="utf-8" />
+    <meta name="viewport" content="width=device-width, initial-scale=1" />
+    <link rel="stylesheet" href="%s/swagger-ui.css" />
+  </head>
+  <body>
+    <div id="swagger-ui"></div>
+    <script src="%s/swagger-ui-bundle.js"></script>
+    <script>
+      window.ui = SwaggerUIBundle({ spec: %s, dom_id: "#swagger-ui" });
+    </script>
+  </body>
+</html>
+`, html.EscapeString(docsTitle(spec, d.Title)), html.EscapeString(cdn), html.EscapeString(cdn), data)), nil
+}
+
+// RedocDocs renders docs with Redoc, loaded from a CDN
+type RedocDocs struct {
+	Title string // Page title (default: the spec title followed by "Documentation")
+	CDN   string // URL of the Redoc standalone bundle (default: the latest from cdn.redoc.ly)
+}
+
+// Render implements DocsRenderer
+func (d RedocDocs) Render(spec *OpenAPISpec) ([]byte, error) {
+	cdn := d.CDN
+	if cdn == "" {
+		cdn = "https://cdn.redoc.ly/redoc/latest/bundles/redoc.standalone.js"
+	}
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(fmt.Sprintf(`<!DOCTYPE html>
+<html>
+  <head>
+    <title>%s</title>
+    <meta charset="utf-8" />
+    <meta name="viewport" content="width=device-width, initial-scale=1" />
+  </head>
+  <body>
+    <div id="redoc"></div>
+    <script src="%s"></script>
+    <script>
+      Redoc.init(%s, {}, document.getElementById("redoc"));
+    </script>
+  </body>
+</html>
+`, html.EscapeString(docsTitle(spec, d.Title)), html.EscapeString(cdn), data)), nil
+}
+
+// docsTitle returns title, defaulting to one derived from the spec
+func docsTitle(spec *OpenAPISpec, title string) string {
+	if title != "" {
+		return title
+	}
+	return spec.Info.Title + " Documentation"
+}
@@ -0,0 +1,144 @@
+package gofastapi
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Enum is implemented by enumerated types. Values lists the allowed values; it is
+// emitted as the schema's enum and request values outside the set are rejected.
+//
+//	type Status string
+//
+//	func (Status) Values() []Status { return []Status{"active", "archived"} }
+type Enum[T any] interface {
+	Values() []T
+}
+
+// IntEnum constrains generic code to integer-backed enums
+type IntEnum[T ~int | ~int8 | ~int16 | ~int32 | ~int64] interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64
+	Enum[T]
+}
+
+// StrEnum constrains generic code to string-backed enums
+type StrEnum[T ~string] interface {
+	~string
+	Enum[T]
+}
+
+// enumValues returns the allowed values of t if it implements Enum[t]
+func enumValues(t reflect.Type) ([]reflect.Value, bool) {
+	method, ok := t.MethodByName("Values")
+	if !ok || method.Type.NumIn() != 1 || method.Type.NumOut() != 1 {
+		return nil, false
+	}
+	out := method.Type.Out(0)
+	if out.Kind() != reflect.Slice || out.Elem() != t || !t.Comparable() {
+		return nil, false
+	}
+
+	list := reflect.Zero(t).MethodByName("Values").Call(nil)[0]
+	values := make([]reflect.Value, list.Len())
+	for i := range values {
+		values[i] = list.Index(i)
+	}
+	return values, true
+}
+
+// enumSchemaValues returns the enum values of t for documentation
+func enumSchemaValues(t reflect.Type) []interface{} {
+	values, ok := enumValues(t)
+	if !ok {
+		return nil
+	}
+	enum := make([]interface{}, len(values))
+	for i, v := range values {
+		enum[i] = v.Interface()
+	}
+	return enum
+}
+
+// enumTypeCache memoizes whether a type can contain enum values
+var enumTypeCache sync.Map
+
+// containsEnum reports whether values of t may hold enum values that need checking
+func containsEnum(t reflect.Type) bool {
+	if cached, ok := enumTypeCache.Load(t); ok {
+		return cached.(bool)
+	}
+	// Assume false while visiting to terminate on recursive types
+	enumTypeCache.Store(t, false)
+
+	result := false
+	if _, ok := enumValues(t); ok {
+		result = true
+	} else {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			result = containsEnum(t.Elem())
+		case reflect.Struct:
+			for i := 0; i < t.NumField() && !result; i++ {
+				if t.Field(i).PkgPath == "" {
+					result = containsEnum(t.Field(i).Type)
+				}
+			}
+		}
+	}
+	enumTypeCache.Store(t, result)
+	return result
+}
+
+// collectEnumErrors records enum values outside their allowed set. Paths use Go field
+// names in the same form as validator namespaces, e.g. "Items[0].Status".
+// Zero values are treated as absent and left to the required rule.
+func collectEnumErrors(v reflect.Value, path string, errs map[string][]string) {
+	if !v.IsValid() || !containsEnum(v.Type()) {
+		return
+	}
+
+	if values, ok := enumValues(v.Type()); ok {
+		if v.IsZero() {
+			return
+		}
+		allowed := make([]interface{}, len(values))
+		for i, candidate := range values {
+			if candidate.Interface() == v.Interface() {
+				return
+			}
+			allowed[i] = candidate.Interface()
+		}
+		errs[path] = append(errs[path], fmt.Sprintf("must be one of %v", allowed))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			collectEnumErrors(v.Elem(), path, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			collectEnumErrors(v.Index(i), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			collectEnumErrors(iter.Value(), fmt.Sprintf("%s[%v]", path, iter.Key().Interface()), errs)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			fieldPath := field.Name
+			if path != "" {
+				fieldPath = path + "." + field.Name
+			}
+			collectEnumErrors(v.Field(i), fieldPath, errs)
+		}
+	}
+}
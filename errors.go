@@ -1,18 +1,27 @@
 package gofastapi
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"sync"
+	"sync/atomic"
 )
 
+// StatusClientClosedRequest is the non-standard status of requests whose client went
+// away before the response was written, as logged by nginx
+const StatusClientClosedRequest = 499
+
 // Error represents a structured API error
 type Error struct {
 	Status  int               `json:"-"`
 	Code    string            `json:"code,omitempty"`
 	Message string            `json:"message"`
 	Details map[string]string `json:"details,omitempty"`
+	Headers map[string]string `json:"-"` // Response headers, e.g. Retry-After
 }
 
 func (e *Error) Error() string {
@@ -51,56 +60,218 @@ func (e *ValidationError) Error() string {
 
 // NewValidationError creates a new validation error
 func NewValidationError(fields map[string][]string) *ValidationError {
+	return NewValidationErrorWithStatus(http.StatusBadRequest, fields)
+}
+
+// NewValidationErrorWithStatus creates a new validation error with a custom status
+func NewValidationErrorWithStatus(status int, fields map[string][]string) *ValidationError {
 	return &ValidationError{
-		Status:  http.StatusBadRequest,
+		Status:  status,
 		Code:    "VALIDATION_ERROR",
 		Message: "Validation failed",
 		Fields:  fields,
 	}
 }
 
+// NewFieldError creates a validation error for business rules checked by a handler or
+// dependency, e.g. {"body.end_date": {"must be after start_date"}}. It responds in the
+// same format and with the same status as request validation failures (400 unless
+// set with SetValidationErrorStatus). Its Status is 0 until a router hands it to an
+// error handler, custom ones included, with the router's validation status filled in.
+func NewFieldError(fields map[string][]string) *ValidationError {
+	return NewValidationErrorWithStatus(0, fields)
+}
+
+// withFieldErrorStatus wraps an error handler so field errors reach it with status
+// in place of Status 0. The error is copied, since one may be shared across routers.
+func withFieldErrorStatus(handler ErrorHandler, status int) ErrorHandler {
+	return func(w http.ResponseWriter, r *http.Request, err error) {
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) && validationErr.Status == 0 {
+			resolved := *validationErr
+			resolved.Status = status
+			if err == error(validationErr) {
+				err = &resolved
+			} else {
+				err = &fieldErrorStatus{err: err, resolved: &resolved}
+			}
+		}
+		handler(w, r, err)
+	}
+}
+
+// fieldErrorStatus keeps the chain of a wrapped field error while errors.As finds
+// the copy carrying the router's validation status
+type fieldErrorStatus struct {
+	err      error
+	resolved *ValidationError
+}
+
+func (e *fieldErrorStatus) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap exposes the original chain, e.g. a DependencyError
+func (e *fieldErrorStatus) Unwrap() error {
+	return e.err
+}
+
+// As resolves *ValidationError targets to the copy with the status filled in
+func (e *fieldErrorStatus) As(target interface{}) bool {
+	if ptr, ok := target.(**ValidationError); ok {
+		*ptr = e.resolved
+		return true
+	}
+	return false
+}
+
 // ErrorResponse is the standard error response structure
 type ErrorResponse struct {
-	Code    string              `json:"code,omitempty"`
-	Message string              `json:"message"`
-	Details map[string]string   `json:"details,omitempty"`
-	Fields  map[string][]string `json:"validation_errors,omitempty"`
+	Code      string              `json:"code,omitempty"`
+	Message   string              `json:"message"`
+	Details   map[string]string   `json:"details,omitempty"`
+	Fields    map[string][]string `json:"validation_errors,omitempty"`
+	RequestID string              `json:"request_id,omitempty"`
 }
 
 // ErrorHandler is the function signature for custom error handlers
 type ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
 
-// defaultErrorHandler is the default error handler
-func defaultErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
+// ErrorEnvelope transforms the standard error response before it is encoded,
+// e.g. to wrap it as {"error": {...}}
+type ErrorEnvelope func(ErrorResponse) interface{}
+
+// newDefaultErrorHandler returns the default error handler, which applies the
+// router's error mappings and, if set, an envelope
+func newDefaultErrorHandler(mappings *errorMappings, envelope ErrorEnvelope, config *atomic.Pointer[routerConfig]) ErrorHandler {
+	return func(w http.ResponseWriter, r *http.Request, err error) {
+		cfg := config.Load()
+		writeErrorResponse(w, r, mappings.apply(err), envelope, cfg.log(), cfg.validationStatus)
+	}
+}
+
+// errorMapping maps errors matching target to an API error
+type errorMapping struct {
+	target error
+	status int
+	code   string
+}
+
+// errorMappings holds a router's error mappings, shared with its default error handler
+type errorMappings struct {
+	list []errorMapping
+	mu   sync.RWMutex
+}
+
+func (m *errorMappings) add(mapping errorMapping) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.list = append(m.list, mapping)
+}
+
+// apply converts err to an API error using the first mapping whose target it matches
+// via errors.Is. Errors that already carry a status are returned unchanged.
+func (m *errorMappings) apply(err error) error {
+	if m == nil || err == nil {
+		return err
+	}
+	var apiErr *Error
+	var validationErr *ValidationError
+	if errors.As(err, &apiErr) || errors.As(err, &validationErr) {
+		return err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, mapping := range m.list {
+		if errors.Is(err, mapping.target) {
+			return &mappedError{
+				apiErr: NewErrorWithCode(mapping.status, mapping.code, mapping.target.Error()),
+				err:    err,
+			}
+		}
+	}
+	return err
+}
+
+// mappedError is a domain error converted by an error mapping
+type mappedError struct {
+	apiErr *Error
+	err    error
+}
+
+func (e *mappedError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap exposes both the API error, for its status, and the original error
+func (e *mappedError) Unwrap() []error {
+	return []error{e.apiErr, e.err}
+}
+
+// writeErrorResponse converts err to an ErrorResponse and writes it as JSON
+func writeErrorResponse(w http.ResponseWriter, r *http.Request, err error, envelope ErrorEnvelope, logger *slog.Logger, validationStatus int) {
+	status, response := toErrorResponse(w, r, err, logger, validationStatus)
+
+	var body interface{} = response
+	if envelope != nil {
+		body = envelope(response)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// toErrorResponse converts err to an ErrorResponse and its status, setting the
+// response headers carried by the error. Validation errors without a status, from
+// NewFieldError, get validationStatus.
+func toErrorResponse(w http.ResponseWriter, r *http.Request, err error, logger *slog.Logger, validationStatus int) (int, ErrorResponse) {
 	var response ErrorResponse
 	status := http.StatusInternalServerError
 
-	switch e := err.(type) {
-	case *Error:
-		status = e.Status
+	// Requests that ran past their route timeout become 504s, and requests the client
+	// gave up on become 499s, which aren't server errors worth logging
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = NewErrorWithCode(http.StatusGatewayTimeout, "TIMEOUT", "Request timed out")
+	} else if errors.Is(err, context.Canceled) {
+		err = NewErrorWithCode(StatusClientClosedRequest, "CLIENT_CLOSED_REQUEST", "Client closed the request")
+	}
+
+	// Unwrap so errors wrapped on the way up (e.g. in a DependencyError or with
+	// fmt.Errorf's %w) keep their status
+	var apiErr *Error
+	var validationErr *ValidationError
+	switch {
+	case errors.As(err, &apiErr):
+		status = apiErr.Status
+		for key, value := range apiErr.Headers {
+			w.Header().Set(key, value)
+		}
 		response = ErrorResponse{
-			Code:    e.Code,
-			Message: e.Message,
-			Details: e.Details,
+			Code:    apiErr.Code,
+			Message: apiErr.Message,
+			Details: apiErr.Details,
+		}
+	case errors.As(err, &validationErr):
+		status = validationErr.Status
+		if status == 0 {
+			status = validationStatus
 		}
-	case *ValidationError:
-		status = e.Status
 		response = ErrorResponse{
-			Code:    e.Code,
-			Message: e.Message,
-			Fields:  e.Fields,
+			Code:    validationErr.Code,
+			Message: validationErr.Message,
+			Fields:  validationErr.Fields,
 		}
 	default:
-		slog.Error("internal server error", "error", err)
+		logger.Error("internal server error", requestAttrs(r, "error", err)...)
 		response = ErrorResponse{
 			Code:    "INTERNAL_ERROR",
 			Message: "An internal error occurred",
 		}
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(response)
+	response.RequestID = RequestIDFromContext(r.Context())
+	return status, response
 }
 
 // WithDetails adds details to an error
@@ -109,6 +280,15 @@ func (e *Error) WithDetails(details map[string]string) *Error {
 	return e
 }
 
+// WithHeader sets a header on the error response, e.g. Retry-After on a 429 or 503
+func (e *Error) WithHeader(key, value string) *Error {
+	if e.Headers == nil {
+		e.Headers = make(map[string]string)
+	}
+	e.Headers[key] = value
+	return e
+}
+
 // WithDetail adds a single detail to an error
 func (e *Error) WithDetail(key, value string) *Error {
 	if e.Details == nil {
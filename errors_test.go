@@ -0,0 +1,120 @@
+package gofastapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestFieldErrorStatus(t *testing.T) {
+	// One error value shared by routers with different validation statuses
+	fieldErr := NewFieldError(map[string][]string{"body.end_date": {"must be after start_date"}})
+	handler := func(ctx context.Context, req struct{}) (string, error) {
+		return "", fieldErr
+	}
+
+	strict := New()
+	strict.SetValidationErrorStatus(http.StatusUnprocessableEntity)
+	problem := New()
+	problem.SetValidationErrorStatus(http.StatusUnprocessableEntity)
+	problem.SetErrorHandler(ProblemDetailsErrorHandler)
+	routers := []struct {
+		name   string
+		router *Router
+		want   int
+	}{{"422", strict, http.StatusUnprocessableEntity}, {"default", New(), http.StatusBadRequest}, {"problem details", problem, http.StatusUnprocessableEntity}}
+	for _, tt := range routers {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.router.POST("/bookings", handler); err != nil {
+				t.Fatal(err)
+			}
+			resp, err := tt.router.TestRequest(http.MethodPost, "/bookings", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+			if fieldErr.Status != 0 {
+				t.Errorf("error status = %d, want the handler's error left unchanged", fieldErr.Status)
+			}
+		})
+	}
+}
+
+func TestErrorEnvelopeKeepsCustomHandler(t *testing.T) {
+	custom := func(w http.ResponseWriter, r *http.Request, err error) {
+		w.WriteHeader(http.StatusTeapot)
+	}
+	envelope := func(resp ErrorResponse) interface{} {
+		return map[string]interface{}{"error": resp}
+	}
+	handler := func(ctx context.Context, req struct{}) (string, error) {
+		return "", NewError(http.StatusNotFound, "missing")
+	}
+
+	// Whichever is set first, the custom handler answers
+	handlerFirst := New()
+	handlerFirst.SetErrorHandler(custom)
+	SetErrorEnvelope(handlerFirst, envelope)
+	envelopeFirst := New()
+	SetErrorEnvelope(envelopeFirst, envelope)
+	envelopeFirst.SetErrorHandler(custom)
+	routers := []struct {
+		name   string
+		router *Router
+	}{{"handler first", handlerFirst}, {"envelope first", envelopeFirst}}
+	for _, tt := range routers {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.router.GET("/items", handler); err != nil {
+				t.Fatal(err)
+			}
+			resp, err := tt.router.TestRequest(http.MethodGet, "/items", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != http.StatusTeapot {
+				t.Errorf("status = %d, want the custom handler's 418", resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestCustomErrorHandlerSeesFieldErrorStatus(t *testing.T) {
+	fieldErr := NewFieldError(map[string][]string{"body.end_date": {"must be after start_date"}})
+	r := New()
+	r.SetValidationErrorStatus(http.StatusUnprocessableEntity)
+	r.SetErrorHandler(func(w http.ResponseWriter, req *http.Request, err error) {
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("error %v is not a ValidationError", err)
+			return
+		}
+		w.WriteHeader(validationErr.Status)
+	})
+	handlers := map[string]error{"/direct": fieldErr, "/wrapped": fmt.Errorf("booking: %w", fieldErr)}
+	for path, handlerErr := range handlers {
+		handlerErr := handlerErr
+		err := r.POST(path, func(ctx context.Context, req struct{}) (string, error) {
+			return "", handlerErr
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for path := range handlers {
+		resp, err := r.TestRequest(http.MethodPost, path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusUnprocessableEntity {
+			t.Errorf("%s: status = %d, want 422", path, resp.StatusCode)
+		}
+	}
+	if fieldErr.Status != 0 {
+		t.Errorf("error status = %d, want the handler's error left unchanged", fieldErr.Status)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/priyanshu-shubham/gofastapi"
@@ -77,40 +78,25 @@ func (d *AuthDependency) Handle(ctx context.Context, req AuthRequest) (AuthRespo
 	}, nil
 }
 
-// Rate Limiting Dependency
+// Rate Limiting Dependency, keyed by the authenticated user
 type RateLimitDependency struct {
-	requests map[string]int
-	limit    int
+	limiter *gofastapi.RateLimiter
 }
 
 type RateLimitRequest struct {
 	UserID string `dep:"auth.UserID"`
 }
 
-type RateLimitResponse struct {
-	Allowed   bool `json:"allowed"`
-	Remaining int  `json:"remaining"`
-}
+type RateLimitResponse = gofastapi.RateLimit
 
 func NewRateLimitDependency(limit int) *RateLimitDependency {
 	return &RateLimitDependency{
-		requests: make(map[string]int),
-		limit:    limit,
+		limiter: gofastapi.NewRateLimiter(limit, time.Minute),
 	}
 }
 
 func (d *RateLimitDependency) Handle(ctx context.Context, req RateLimitRequest) (RateLimitResponse, error) {
-	d.requests[req.UserID]++
-	remaining := d.limit - d.requests[req.UserID]
-
-	if remaining < 0 {
-		return RateLimitResponse{}, gofastapi.NewError(http.StatusTooManyRequests, "Rate limit exceeded")
-	}
-
-	return RateLimitResponse{
-		Allowed:   true,
-		Remaining: remaining,
-	}, nil
+	return d.limiter.Allow(req.UserID)
 }
 
 // Create Post Handler
@@ -200,6 +186,11 @@ type ListPostsResponse struct {
 	TotalPages int           `json:"total_pages"`
 }
 
+// Headers exposes the total count for clients that read it from a header
+func (r ListPostsResponse) Headers() http.Header {
+	return http.Header{"X-Total-Count": {strconv.Itoa(r.Total)}}
+}
+
 type PostSummary struct {
 	ID        string    `json:"id"`
 	Title     string    `json:"title"`
@@ -304,9 +295,12 @@ func main() {
 	r.RegisterDependency("auth", NewAuthDependency(), gofastapi.SecuritySchemeBearer)
 	r.RegisterDependency("rate_limit", NewRateLimitDependency(100))
 
+	// Require authentication on every route unless exempted
+	r.RequireDependencies("auth")
+
 	// Public endpoints
-	r.GET("/health", HealthHandler)
-	r.GET("/posts", ListPostsHandler)
+	r.GET("/health", HealthHandler, gofastapi.SkipDependency("auth"))
+	r.GET("/posts", ListPostsHandler, gofastapi.SkipDependency("auth"))
 
 	// Protected endpoints
 	r.POST("/categories/{category_id}/posts", CreatePostHandler)
@@ -62,7 +62,6 @@ func streamSystemAlerts(ctx context.Context, req AlertStreamRequest) (iter.Seq[g
 
 				event := gofastapi.EventData[SystemAlert]{
 					Event: fmt.Sprintf("alert-%s", level),
-					ID:    fmt.Sprintf("alert-%d", counter),
 					Retry: 5000, // Retry after 5 seconds if connection lost
 					Data: SystemAlert{
 						Level:   level,
@@ -111,8 +110,8 @@ func main() {
 	// Register SSE endpoints
 	log.Println("Registering SSE routes...")
 
-	// POST SSE endpoint for system alerts
-	if err := router.SSEPOST("/alerts/stream", streamSystemAlerts); err != nil {
+	// POST SSE endpoint for system alerts, with event IDs assigned automatically
+	if err := router.SSEPOST("/alerts/stream", streamSystemAlerts, gofastapi.WithAutoEventID()); err != nil {
 		log.Fatalf("Failed to register alert stream route: %v", err)
 	}
 
@@ -1,12 +1,18 @@
 package gofastapi
 
 import (
+	"encoding"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
 )
 
 // FieldExtractor extracts a field value from an HTTP request
@@ -25,35 +31,136 @@ func (e *PathExtractor) Extract(r *http.Request, vars map[string]string, body []
 	if !ok {
 		return nil, fmt.Errorf("path parameter %s not found", e.paramName)
 	}
-	return convertValue(value, e.fieldType)
+	return convertParam("path."+e.paramName, value, e.fieldType)
+}
+
+// PathParamsExtractor extracts all path variables as a map
+type PathParamsExtractor struct {
+	fieldType reflect.Type
+}
+
+func (e *PathParamsExtractor) Extract(r *http.Request, vars map[string]string, body []byte) (interface{}, error) {
+	params := make(map[string]string, len(vars))
+	for name, value := range vars {
+		params[name] = value
+	}
+	return params, nil
+}
+
+// RequestExtractor injects the raw *http.Request, e.g. for dependencies that need the
+// client address
+type RequestExtractor struct {
+	fieldType reflect.Type
+}
+
+func (e *RequestExtractor) Extract(r *http.Request, vars map[string]string, body []byte) (interface{}, error) {
+	return r, nil
+}
+
+// RoutePatternExtractor extracts the matched route template, e.g. "/users/{id}"
+type RoutePatternExtractor struct {
+	fieldType reflect.Type
+}
+
+func (e *RoutePatternExtractor) Extract(r *http.Request, vars map[string]string, body []byte) (interface{}, error) {
+	return RoutePattern(r), nil
+}
+
+// RoutePattern returns the template of the route matched for r, or "" if none matched
+func RoutePattern(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return ""
+	}
+	pattern, err := route.GetPathTemplate()
+	if err != nil {
+		return ""
+	}
+	return pattern
 }
 
+// maxJSONQueryParamSize limits the size of a JSON-encoded query parameter.
+// Most servers and proxies cap the full URL at around 8KB anyway.
+const maxJSONQueryParamSize = 8 << 10
+
 // QueryExtractor extracts query parameters
 type QueryExtractor struct {
-	paramName string
-	fieldType reflect.Type
+	paramName   string
+	fieldType   reflect.Type
+	jsonEncoded bool   // Struct, map or slice-of-struct values are passed as JSON
+	delimiter   string // Separator of list values; empty to split only on repeated keys
 }
 
 func (e *QueryExtractor) Extract(r *http.Request, vars map[string]string, body []byte) (interface{}, error) {
-	value := r.URL.Query().Get(e.paramName)
-	if value == "" && e.fieldType.Kind() != reflect.Bool {
+	values, present := r.URL.Query()[e.paramName]
+	if isListType(e.fieldType) && !e.jsonEncoded {
+		// Lists collect every occurrence of the key, e.g. ?tag=a,b&tag=c is [a b c]
+		return convertListParam("query."+e.paramName, splitList(values, e.delimiter), e.fieldType)
+	}
+	var value string
+	if present {
+		value = values[0]
+	}
+	if value == "" {
+		if present && e.fieldType.Kind() == reflect.Bool {
+			return true, nil // Presence-only flag, e.g. ?draft
+		}
 		return reflect.Zero(e.fieldType).Interface(), nil
 	}
-	return convertValue(value, e.fieldType)
+	if e.jsonEncoded {
+		return decodeJSONQueryValue(e.paramName, value, e.fieldType)
+	}
+	return convertParam("query."+e.paramName, value, e.fieldType)
+}
+
+// decodeJSONQueryValue unmarshals a JSON-encoded query parameter, e.g. ?filter={"status":"open"}
+func decodeJSONQueryValue(paramName, value string, targetType reflect.Type) (interface{}, error) {
+	if len(value) > maxJSONQueryParamSize {
+		return nil, NewError(http.StatusBadRequest, fmt.Sprintf("query parameter %s exceeds %d bytes", paramName, maxJSONQueryParamSize))
+	}
+	result := reflect.New(targetType)
+	if err := json.Unmarshal([]byte(value), result.Interface()); err != nil {
+		return nil, NewError(http.StatusBadRequest, fmt.Sprintf("query parameter %s must be valid JSON", paramName))
+	}
+	return result.Elem().Interface(), nil
+}
+
+// isJSONQueryType reports whether a query field type is bound from a JSON-encoded
+// value rather than a scalar or comma-separated list of scalars
+func isJSONQueryType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.String() == "time.Time" {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		return true
+	case reflect.Slice, reflect.Array:
+		return isJSONQueryType(t.Elem())
+	default:
+		return false
+	}
 }
 
 // HeaderExtractor extracts headers
 type HeaderExtractor struct {
 	headerName string
 	fieldType  reflect.Type
+	delimiter  string // Separator of list values; empty to split only on repeated headers
 }
 
 func (e *HeaderExtractor) Extract(r *http.Request, vars map[string]string, body []byte) (interface{}, error) {
+	if isListType(e.fieldType) {
+		// Lists collect every occurrence of a repeated header, e.g. X-Forwarded-For
+		return convertListParam("header."+e.headerName, splitList(r.Header.Values(e.headerName), e.delimiter), e.fieldType)
+	}
 	value := r.Header.Get(e.headerName)
 	if value == "" {
 		return reflect.Zero(e.fieldType).Interface(), nil
 	}
-	return convertValue(value, e.fieldType)
+	return convertParam("header."+e.headerName, value, e.fieldType)
 }
 
 // JSONExtractor extracts fields from JSON body
@@ -63,6 +170,12 @@ type JSONExtractor struct {
 }
 
 func (e *JSONExtractor) Extract(r *http.Request, vars map[string]string, body []byte) (interface{}, error) {
+	return e.extract(body, false)
+}
+
+// extract decodes the field from body; with lenientArrays, a scalar or object sent
+// for a slice field is bound as a one-element slice
+func (e *JSONExtractor) extract(body []byte, lenientArrays bool) (interface{}, error) {
 	if len(body) == 0 {
 		return reflect.Zero(e.fieldType).Interface(), nil
 	}
@@ -77,10 +190,15 @@ func (e *JSONExtractor) Extract(r *http.Request, vars map[string]string, body []
 		return reflect.ValueOf(result).Elem().Interface(), nil
 	}
 
-	value, ok := data[e.jsonPath]
+	value, ok := lookupJSONPath(data, e.jsonPath)
 	if !ok {
 		return reflect.Zero(e.fieldType).Interface(), nil
 	}
+	if lenientArrays && isLenientSliceType(e.fieldType) {
+		if _, isArray := value.([]interface{}); !isArray && value != nil {
+			value = []interface{}{value}
+		}
+	}
 
 	// Re-marshal and unmarshal to handle complex types
 	jsonBytes, err := json.Marshal(value)
@@ -88,6 +206,15 @@ func (e *JSONExtractor) Extract(r *http.Request, vars map[string]string, body []
 		return nil, err
 	}
 
+	// Interface fields registered as unions decode into the variant the discriminator names
+	if union, ok := lookupUnion(e.fieldType); ok && value != nil {
+		variant, err := union.decode(jsonBytes, "body."+e.jsonPath)
+		if err != nil {
+			return nil, err
+		}
+		return variant.Interface(), nil
+	}
+
 	result := reflect.New(e.fieldType).Interface()
 	if err := json.Unmarshal(jsonBytes, result); err != nil {
 		return nil, err
@@ -96,11 +223,317 @@ func (e *JSONExtractor) Extract(r *http.Request, vars map[string]string, body []
 	return reflect.ValueOf(result).Elem().Interface(), nil
 }
 
+// isLenientSliceType reports whether t is a slice a lone JSON value can be wrapped into.
+// []byte is excluded since JSON encodes it as a base64 string.
+func isLenientSliceType(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8
+}
+
+// lookupJSONPath finds a value by key or, failing that, by dotted path through nested
+// objects, e.g. "address.zip"
+func lookupJSONPath(data map[string]interface{}, path string) (interface{}, bool) {
+	if value, ok := data[path]; ok {
+		return value, true
+	}
+	head, rest, nested := strings.Cut(path, ".")
+	if !nested {
+		return nil, false
+	}
+	child, ok := data[head].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	return lookupJSONPath(child, rest)
+}
+
+// sourceRef is a single location in a multi-source tag, e.g. "header:X-Tenant-ID"
+type sourceRef struct {
+	in   string // path, query or header
+	name string
+}
+
+// parseSourceTag parses a multi-source tag like "header:X-Tenant-ID,query:tenant"
+func parseSourceTag(tag string) ([]sourceRef, error) {
+	var sources []sourceRef
+	for _, part := range strings.Split(tag, ",") {
+		in, name, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid source %q, expected location:name", part)
+		}
+		switch in {
+		case "path", "query", "header":
+		default:
+			return nil, fmt.Errorf("unsupported source location %q", in)
+		}
+		sources = append(sources, sourceRef{in: in, name: name})
+	}
+	return sources, nil
+}
+
+// MultiSourceExtractor extracts a value from the first non-empty of several sources
+type MultiSourceExtractor struct {
+	sources   []sourceRef
+	fieldType reflect.Type
+}
+
+func (e *MultiSourceExtractor) Extract(r *http.Request, vars map[string]string, body []byte) (interface{}, error) {
+	for _, source := range e.sources {
+		var value string
+		switch source.in {
+		case "path":
+			value = vars[source.name]
+		case "query":
+			value = r.URL.Query().Get(source.name)
+		case "header":
+			value = r.Header.Get(source.name)
+		}
+		if value != "" {
+			return convertParam(source.in+"."+source.name, value, e.fieldType)
+		}
+	}
+	return reflect.Zero(e.fieldType).Interface(), nil
+}
+
+// QueryStructExtractor binds query parameters into the fields of a struct field
+// tagged in:"query", grouping related parameters such as pagination
+type QueryStructExtractor struct {
+	extractors map[int]FieldExtractor
+	fieldType  reflect.Type
+}
+
+func (e *QueryStructExtractor) Extract(r *http.Request, vars map[string]string, body []byte) (interface{}, error) {
+	group := reflect.New(e.fieldType).Elem()
+	for fieldIdx, extractor := range e.extractors {
+		value, err := extractor.Extract(r, vars, body)
+		if err != nil {
+			return nil, err
+		}
+		if value != nil {
+			field := group.Field(fieldIdx)
+			fieldValue := reflect.ValueOf(value)
+			if fieldValue.Type().ConvertibleTo(field.Type()) {
+				field.Set(fieldValue.Convert(field.Type()))
+			}
+		}
+	}
+	return group.Interface(), nil
+}
+
+// compileQueryStruct compiles the extractors of a struct field tagged in:"query",
+// whose fields must be query parameters or further query structs
+func compileQueryStruct(field reflect.StructField, tags tagMapping) (*QueryStructExtractor, error) {
+	if in := field.Tag.Get("in"); in != "query" {
+		return nil, fmt.Errorf("field %s has unsupported in tag %q; only in:\"query\" is supported", field.Name, in)
+	}
+	if field.Type.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("field %s with in tag must be a struct", field.Name)
+	}
+	extractors, _, err := compileStructExtractors(field.Type, tags)
+	if err != nil {
+		return nil, fmt.Errorf("field %s: %w", field.Name, err)
+	}
+	for fieldIdx, extractor := range extractors {
+		switch extractor.(type) {
+		case *QueryExtractor, *QueryStructExtractor:
+		default:
+			return nil, fmt.Errorf("field %s.%s: fields of in:\"query\" structs must have query tags",
+				field.Name, field.Type.Field(fieldIdx).Name)
+		}
+	}
+	return &QueryStructExtractor{extractors: extractors, fieldType: field.Type}, nil
+}
+
+// boundField is a request field and its extractor, named by its path from the request
+// struct, e.g. "Pagination.Page"
+type boundField struct {
+	name      string
+	extractor FieldExtractor
+}
+
+// flattenExtractors lists the extractors of a struct in field order, replacing query
+// structs with the extractors of their fields
+func flattenExtractors(structType reflect.Type, extractors map[int]FieldExtractor) []boundField {
+	var fields []boundField
+	for fieldIdx := 0; fieldIdx < structType.NumField(); fieldIdx++ {
+		name := structType.Field(fieldIdx).Name
+		switch e := extractors[fieldIdx].(type) {
+		case nil:
+		case *QueryStructExtractor:
+			for _, inner := range flattenExtractors(e.fieldType, e.extractors) {
+				fields = append(fields, boundField{name: name + "." + inner.name, extractor: inner.extractor})
+			}
+		default:
+			fields = append(fields, boundField{name: name, extractor: e})
+		}
+	}
+	return fields
+}
+
+// compileBodyFields maps JSON body keys to the types of the fields they populate
+func compileBodyFields(extractors map[int]FieldExtractor) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for _, extractor := range extractors {
+		if e, ok := extractor.(*JSONExtractor); ok {
+			fields[e.jsonPath] = e.fieldType
+		}
+	}
+	return fields
+}
+
+// findUnknownBodyFields reports body keys, including nested ones, that don't map to a
+// request field. Keys are namespaced like validation errors, e.g. "body.titel" or
+// "body.items[0].nmae".
+func findUnknownBodyFields(body []byte, bodyFields map[string]reflect.Type) map[string][]string {
+	var data map[string]json.RawMessage
+	if len(body) == 0 || json.Unmarshal(body, &data) != nil {
+		return nil
+	}
+
+	unknown := make(map[string][]string)
+	collectUnknownBodyFields(data, "", bodyFields, unknown)
+	return unknown
+}
+
+// collectUnknownBodyFields checks the keys of an object at a dotted path prefix.
+// Keys that are parents of dotted field paths (e.g. "address" for "address.zip")
+// are checked recursively.
+func collectUnknownBodyFields(data map[string]json.RawMessage, prefix string, bodyFields map[string]reflect.Type, unknown map[string][]string) {
+	for key, raw := range data {
+		path := prefix + key
+		if fieldType, ok := bodyFields[path]; ok {
+			collectUnknownJSONFields(raw, fieldType, "body."+path, unknown)
+			continue
+		}
+
+		var nested map[string]json.RawMessage
+		if hasBodyFieldPrefix(bodyFields, path+".") && json.Unmarshal(raw, &nested) == nil {
+			collectUnknownBodyFields(nested, path+".", bodyFields, unknown)
+			continue
+		}
+		unknown["body."+path] = append(unknown["body."+path], "unknown field")
+	}
+}
+
+// collectUnknownJSONFields walks the JSON value raw decoded into type t and records
+// every object key, at any depth, that encoding/json would ignore
+func collectUnknownJSONFields(raw json.RawMessage, t reflect.Type, path string, unknown map[string][]string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	// Custom decoders accept whatever keys they like
+	if t.Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		return
+	}
+	// Union fields are checked against the variant their discriminator names
+	if union, isUnion := lookupUnion(t); isUnion {
+		if variant, _, found := union.variantOf(raw); found {
+			collectUnknownJSONFields(raw, variant, path, unknown)
+		}
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		var object map[string]json.RawMessage
+		if json.Unmarshal(raw, &object) != nil {
+			return
+		}
+		fields := jsonFieldTypes(t)
+		for key, value := range object {
+			fieldType, ok := fields[key]
+			if !ok {
+				// encoding/json falls back to a case-insensitive match
+				for name, candidate := range fields {
+					if strings.EqualFold(name, key) {
+						fieldType, ok = candidate, true
+						break
+					}
+				}
+			}
+			if !ok {
+				unknown[path+"."+key] = append(unknown[path+"."+key], "unknown field")
+				continue
+			}
+			collectUnknownJSONFields(value, fieldType, path+"."+key, unknown)
+		}
+	case reflect.Map:
+		var object map[string]json.RawMessage
+		if json.Unmarshal(raw, &object) != nil {
+			return
+		}
+		for key, value := range object {
+			collectUnknownJSONFields(value, t.Elem(), path+"."+key, unknown)
+		}
+	case reflect.Slice, reflect.Array:
+		var items []json.RawMessage
+		if json.Unmarshal(raw, &items) != nil {
+			return
+		}
+		for i, item := range items {
+			collectUnknownJSONFields(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), unknown)
+		}
+	}
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// jsonFieldTypes maps the JSON names of a struct's fields to their types, promoting
+// the fields of untagged embedded structs like encoding/json
+func jsonFieldTypes(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		jsonTag := field.Tag.Get("json")
+		if jsonTag == "-" {
+			continue
+		}
+		name := strings.Split(jsonTag, ",")[0]
+		if field.Anonymous && name == "" {
+			embeddedType := field.Type
+			if embeddedType.Kind() == reflect.Ptr {
+				embeddedType = embeddedType.Elem()
+			}
+			if embeddedType.Kind() == reflect.Struct {
+				embedded = append(embedded, embeddedType)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+	// Direct fields take precedence over promoted ones
+	for _, embeddedType := range embedded {
+		for name, fieldType := range jsonFieldTypes(embeddedType) {
+			if _, ok := fields[name]; !ok {
+				fields[name] = fieldType
+			}
+		}
+	}
+	return fields
+}
+
+// hasBodyFieldPrefix reports whether any body field path starts with prefix
+func hasBodyFieldPrefix(bodyFields map[string]reflect.Type, prefix string) bool {
+	for path := range bodyFields {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // DependencyExtractor extracts values from resolved dependencies
 type DependencyExtractor struct {
 	depName   string
 	fieldPath []string // For nested access like "auth.user_id"
 	fieldType reflect.Type
+	lazy      bool // The field is a func() (T, error) resolving the dependency on demand
 }
 
 func (e *DependencyExtractor) Extract(r *http.Request, vars map[string]string, body []byte) (interface{}, error) {
@@ -108,8 +541,234 @@ func (e *DependencyExtractor) Extract(r *http.Request, vars map[string]string, b
 	return nil, nil
 }
 
+// extractorSource returns the location-qualified name of the value an extractor reads,
+// e.g. "query.page" or "body.title". It returns "" for sources without a request location.
+func extractorSource(extractor FieldExtractor) string {
+	switch e := extractor.(type) {
+	case *PathExtractor:
+		return "path." + e.paramName
+	case *QueryExtractor:
+		return "query." + e.paramName
+	case *HeaderExtractor:
+		return "header." + e.headerName
+	case *JSONExtractor:
+		return "body." + e.jsonPath
+	case *MultiSourceExtractor:
+		return e.sources[0].in + "." + e.sources[0].name
+	default:
+		return ""
+	}
+}
+
+// compileFieldSources maps struct field names to the location-qualified names of their sources
+func compileFieldSources(structType reflect.Type, extractors map[int]FieldExtractor) map[string]string {
+	sources := make(map[string]string)
+	for _, field := range flattenExtractors(structType, extractors) {
+		if source := extractorSource(field.extractor); source != "" {
+			sources[field.name] = source
+		}
+	}
+	return sources
+}
+
+// Converter parses a raw string parameter into a value of a specific type
+type Converter func(string) (interface{}, error)
+
+var (
+	converters = map[reflect.Type]Converter{
+		reflect.TypeOf(time.Duration(0)): func(s string) (interface{}, error) {
+			return time.ParseDuration(s)
+		},
+		reflect.TypeOf(time.Time{}): func(s string) (interface{}, error) {
+			return time.Parse(time.RFC3339, s)
+		},
+	}
+	convertersMu sync.RWMutex
+)
+
+// RegisterConverter registers a parser for path, query and header values of type t,
+// consulted before the built-in conversions. Converters are process-wide and apply to
+// every router.
+func RegisterConverter(t reflect.Type, fn Converter) {
+	convertersMu.Lock()
+	defer convertersMu.Unlock()
+	converters[t] = fn
+}
+
+// hasConverter reports whether a custom converter is registered for t
+func hasConverter(t reflect.Type) bool {
+	convertersMu.RLock()
+	defer convertersMu.RUnlock()
+	_, ok := converters[t]
+	return ok
+}
+
+// boolTokens maps lowercase parameter values to the booleans they mean
+var (
+	boolTokens = map[string]bool{
+		"true": true, "1": true, "yes": true, "on": true, "t": true, "y": true,
+		"false": false, "0": false, "no": false, "off": false, "f": false, "n": false,
+	}
+	boolTokensMu sync.RWMutex
+)
+
+// SetBoolTokens replaces the values accepted for boolean path, query and header
+// parameters, matched case-insensitively. By default true, 1, yes, on, t and y are
+// true and false, 0, no, off, f and n are false. JSON body booleans stay strict. The
+// tokens are process-wide and apply to every router.
+func SetBoolTokens(truthy, falsy []string) {
+	tokens := make(map[string]bool, len(truthy)+len(falsy))
+	for _, token := range truthy {
+		tokens[strings.ToLower(token)] = true
+	}
+	for _, token := range falsy {
+		tokens[strings.ToLower(token)] = false
+	}
+	boolTokensMu.Lock()
+	defer boolTokensMu.Unlock()
+	boolTokens = tokens
+}
+
+// parseBool parses a boolean parameter value case-insensitively
+func parseBool(value string) (bool, error) {
+	boolTokensMu.RLock()
+	defer boolTokensMu.RUnlock()
+	b, ok := boolTokens[strings.ToLower(strings.TrimSpace(value))]
+	if !ok {
+		return false, fmt.Errorf("invalid boolean %q", value)
+	}
+	return b, nil
+}
+
+// convertParam converts a raw parameter value, reporting failures as a 400 naming the parameter
+func convertParam(param, value string, targetType reflect.Type) (interface{}, error) {
+	result, err := convertValue(value, targetType)
+	if err != nil {
+		return nil, NewErrorWithCode(http.StatusBadRequest, "INVALID_PARAMETER",
+			fmt.Sprintf("invalid value for %s: %v", param, err)).WithDetail("parameter", param)
+	}
+	return result, nil
+}
+
+// isUUIDType reports whether t looks like a 16-byte UUID type such as google/uuid's
+// uuid.UUID. Detection is by shape and name so the package needs no UUID dependency.
+func isUUIDType(t reflect.Type) bool {
+	return t.Name() == "UUID" && t.Kind() == reflect.Array && t.Len() == 16 && t.Elem().Kind() == reflect.Uint8
+}
+
+// parseUUID parses a UUID using the type's UnmarshalText, which google/uuid implements
+func parseUUID(value string, t reflect.Type) (interface{}, error) {
+	ptr := reflect.New(t)
+	unmarshaler, ok := ptr.Interface().(encoding.TextUnmarshaler)
+	if !ok {
+		return nil, fmt.Errorf("unsupported type: %v", t)
+	}
+	if err := unmarshaler.UnmarshalText([]byte(value)); err != nil {
+		return nil, fmt.Errorf("invalid UUID %q", value)
+	}
+	return ptr.Elem().Interface(), nil
+}
+
+// listDelimiterNone is the delimiter tag value that disables splitting list values
+const listDelimiterNone = "none"
+
+// listDelimiter returns the separator of a list field's values from its delimiter tag:
+// a comma by default, or "" for delimiter:"none"
+func listDelimiter(field reflect.StructField) (string, error) {
+	delimiter, ok := field.Tag.Lookup("delimiter")
+	if !ok {
+		return ",", nil
+	}
+	if !isListType(field.Type) {
+		return "", fmt.Errorf("field %s: delimiter tag requires a slice field", field.Name)
+	}
+	switch delimiter {
+	case "":
+		return "", fmt.Errorf("field %s: delimiter must not be empty; use delimiter:\"none\" to disable splitting", field.Name)
+	case listDelimiterNone:
+		return "", nil
+	}
+	return delimiter, nil
+}
+
+// isListType reports whether t is a slice bound from a list of scalar values
+func isListType(t reflect.Type) bool {
+	if t.Kind() != reflect.Slice {
+		return false
+	}
+	convertersMu.RLock()
+	_, converted := converters[t]
+	convertersMu.RUnlock()
+	return !converted
+}
+
+// splitList splits each of values on delimiter, or keeps them whole if delimiter is
+// empty, dropping empty values
+func splitList(values []string, delimiter string) []string {
+	var parts []string
+	for _, value := range values {
+		if delimiter == "" {
+			parts = append(parts, value)
+			continue
+		}
+		parts = append(parts, strings.Split(value, delimiter)...)
+	}
+	return slices.DeleteFunc(parts, func(part string) bool { return strings.TrimSpace(part) == "" })
+}
+
+// convertListParam converts list values, reporting failures as a 400 naming the parameter
+func convertListParam(param string, parts []string, targetType reflect.Type) (interface{}, error) {
+	if len(parts) == 0 {
+		return reflect.Zero(targetType).Interface(), nil
+	}
+	result, err := convertList(parts, targetType)
+	if err != nil {
+		return nil, NewErrorWithCode(http.StatusBadRequest, "INVALID_PARAMETER",
+			fmt.Sprintf("invalid value for %s: %v", param, err)).WithDetail("parameter", param)
+	}
+	return result, nil
+}
+
+// convertList converts the elements of a list to a slice of targetType
+func convertList(parts []string, targetType reflect.Type) (interface{}, error) {
+	slice := reflect.MakeSlice(targetType, len(parts), len(parts))
+	elemType := targetType.Elem()
+	for i, part := range parts {
+		elem, err := convertValue(strings.TrimSpace(part), elemType)
+		if err != nil {
+			return nil, err
+		}
+		slice.Index(i).Set(reflect.ValueOf(elem).Convert(elemType)) // Parsed ints are int64, etc.
+	}
+	return slice.Interface(), nil
+}
+
 // convertValue converts string values to the target type
 func convertValue(value string, targetType reflect.Type) (interface{}, error) {
+	convertersMu.RLock()
+	converter, ok := converters[targetType]
+	convertersMu.RUnlock()
+	if ok {
+		result, err := converter(value)
+		if err != nil {
+			return nil, err
+		}
+		if result == nil {
+			return reflect.Zero(targetType).Interface(), nil
+		}
+		if resultValue := reflect.ValueOf(result); resultValue.Type() != targetType {
+			if !resultValue.Type().ConvertibleTo(targetType) {
+				return nil, fmt.Errorf("converter for %v returned %v", targetType, resultValue.Type())
+			}
+			return resultValue.Convert(targetType).Interface(), nil
+		}
+		return result, nil
+	}
+
+	if isUUIDType(targetType) {
+		return parseUUID(value, targetType)
+	}
+
 	switch targetType.Kind() {
 	case reflect.String:
 		return value, nil
@@ -120,20 +779,10 @@ func convertValue(value string, targetType reflect.Type) (interface{}, error) {
 	case reflect.Float32, reflect.Float64:
 		return strconv.ParseFloat(value, targetType.Bits())
 	case reflect.Bool:
-		return strconv.ParseBool(value)
+		return parseBool(value)
 	case reflect.Slice:
 		// Handle comma-separated values for slices
-		parts := strings.Split(value, ",")
-		slice := reflect.MakeSlice(targetType, len(parts), len(parts))
-		elemType := targetType.Elem()
-		for i, part := range parts {
-			elem, err := convertValue(strings.TrimSpace(part), elemType)
-			if err != nil {
-				return nil, err
-			}
-			slice.Index(i).Set(reflect.ValueOf(elem))
-		}
-		return slice.Interface(), nil
+		return convertList(strings.Split(value, ","), targetType)
 	default:
 		return nil, fmt.Errorf("unsupported type: %v", targetType)
 	}
@@ -0,0 +1,59 @@
+package gofastapi
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+// FileStream is a response type for file downloads. Handlers returning one (or a
+// pointer to one) have the content served with http.ServeContent, which answers
+// Range and If-Range requests with 206 Partial Content and If-Modified-Since with
+// 304, without buffering the file. ReadSeeker is closed afterwards if it is an io.Closer.
+type FileStream struct {
+	Name        string        // File name, used for Content-Disposition and to infer ContentType
+	ContentType string        // Media type (default: inferred from Name, else sniffed)
+	ReadSeeker  io.ReadSeeker // File content
+	ModTime     time.Time     // Last modification time; zero omits Last-Modified
+	Attachment  bool          // Ask browsers to download rather than display the file
+}
+
+var fileStreamType = reflect.TypeOf(FileStream{})
+
+// isFileStreamType reports whether t is FileStream or *FileStream
+func isFileStreamType(t reflect.Type) bool {
+	return t == fileStreamType || (t.Kind() == reflect.Ptr && t.Elem() == fileStreamType)
+}
+
+// serveFileStream writes a FileStream response
+func serveFileStream(w http.ResponseWriter, r *http.Request, resp interface{}) error {
+	var file *FileStream
+	switch v := resp.(type) {
+	case FileStream:
+		file = &v
+	case *FileStream:
+		file = v
+	}
+	if file == nil || file.ReadSeeker == nil {
+		return fmt.Errorf("file stream has no content")
+	}
+	if closer, ok := file.ReadSeeker.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	if file.ContentType != "" {
+		w.Header().Set("Content-Type", file.ContentType)
+	}
+	if file.Name != "" {
+		disposition := "inline"
+		if file.Attachment {
+			disposition = "attachment"
+		}
+		w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": file.Name}))
+	}
+	http.ServeContent(w, r, file.Name, file.ModTime, file.ReadSeeker)
+	return nil
+}
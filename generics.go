@@ -0,0 +1,57 @@
+package gofastapi
+
+import "context"
+
+// funcDependency adapts a plain function to the Handle-method dependency style
+type funcDependency[Req, Resp any] struct {
+	fn func(context.Context, Req) (Resp, error)
+}
+
+func (d funcDependency[Req, Resp]) Handle(ctx context.Context, req Req) (Resp, error) {
+	return d.fn(ctx, req)
+}
+
+// RegisterDependency registers a function as a dependency. Unlike Router.RegisterDependency,
+// the signature is checked at compile time.
+func RegisterDependency[Req, Resp any](r *Router, name string, fn func(context.Context, Req) (Resp, error), opts ...DependencyOption) error {
+	return r.RegisterDependency(name, funcDependency[Req, Resp]{fn: fn}, opts...)
+}
+
+// RouteRegistrar is implemented by Router and SubRouter
+type RouteRegistrar interface {
+	GET(path string, handler interface{}, opts ...RouteOption) error
+	POST(path string, handler interface{}, opts ...RouteOption) error
+	PUT(path string, handler interface{}, opts ...RouteOption) error
+	PATCH(path string, handler interface{}, opts ...RouteOption) error
+	DELETE(path string, handler interface{}, opts ...RouteOption) error
+}
+
+var (
+	_ RouteRegistrar = (*Router)(nil)
+	_ RouteRegistrar = (*SubRouter)(nil)
+)
+
+// Get registers a GET route with a compile-time checked handler signature
+func Get[Req, Resp any](r RouteRegistrar, path string, handler func(context.Context, Req) (Resp, error), opts ...RouteOption) error {
+	return r.GET(path, handler, opts...)
+}
+
+// Post registers a POST route with a compile-time checked handler signature
+func Post[Req, Resp any](r RouteRegistrar, path string, handler func(context.Context, Req) (Resp, error), opts ...RouteOption) error {
+	return r.POST(path, handler, opts...)
+}
+
+// Put registers a PUT route with a compile-time checked handler signature
+func Put[Req, Resp any](r RouteRegistrar, path string, handler func(context.Context, Req) (Resp, error), opts ...RouteOption) error {
+	return r.PUT(path, handler, opts...)
+}
+
+// Patch registers a PATCH route with a compile-time checked handler signature
+func Patch[Req, Resp any](r RouteRegistrar, path string, handler func(context.Context, Req) (Resp, error), opts ...RouteOption) error {
+	return r.PATCH(path, handler, opts...)
+}
+
+// Delete registers a DELETE route with a compile-time checked handler signature
+func Delete[Req, Resp any](r RouteRegistrar, path string, handler func(context.Context, Req) (Resp, error), opts ...RouteOption) error {
+	return r.DELETE(path, handler, opts...)
+}
@@ -3,11 +3,17 @@ package gofastapi
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
+	"log/slog"
+	"maps"
+	"mime"
 	"net/http"
 	"reflect"
+	"runtime/debug"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/gorilla/mux"
 )
@@ -15,19 +21,33 @@ import (
 // HandlerFunc is the signature for route handlers
 type HandlerFunc interface{}
 
+// ResponseEnvelope transforms a handler's response before it is encoded as JSON,
+// e.g. to wrap it as {"data": ..., "meta": ...}
+type ResponseEnvelope func(resp interface{}) interface{}
+
 // CompiledHandler represents a pre-compiled handler
 type CompiledHandler struct {
-	handlerFunc  reflect.Value
-	reqType      reflect.Type
-	respType     reflect.Type
-	extractors   map[int]FieldExtractor
-	validators   map[int]string
-	dependencies map[int]string // field index -> dependency name
-	hasJSONBody  bool
+	handlerFunc         reflect.Value
+	reqType             reflect.Type
+	respType            reflect.Type
+	extractors          map[int]FieldExtractor
+	validators          map[int]string
+	fieldSources        map[string]string // field name -> source-qualified name, e.g. "query.page"
+	dependencies        map[int]string    // field index -> dependency name
+	hasJSONBody         bool
+	bodyFields          map[string]reflect.Type // JSON body key -> field type
+	strictBody          bool                    // Reject unknown body fields
+	timeout             time.Duration           // Deadline applied to the request context; 0 disables
+	contentType         string                  // Required JSON body media type; empty accepts any
+	responseContentType string                  // Media type of JSON responses; empty for application/json
+	hooks               *lifecycleHooks
+	interceptors        []Interceptor // Route interceptors, run inside the router-wide ones
+	anyOf               [][]string    // Groups of dependencies of which any one must succeed
+	config              *atomic.Pointer[routerConfig]
 }
 
 // extractHandlerMetadata extracts dependencies and JSON body info from request type
-func extractHandlerMetadata(reqType reflect.Type) (map[int]string, bool) {
+func extractHandlerMetadata(reqType reflect.Type, tags tagMapping) (map[int]string, bool) {
 	dependencies := make(map[int]string)
 	hasJSONBody := false
 
@@ -38,7 +58,7 @@ func extractHandlerMetadata(reqType reflect.Type) (map[int]string, bool) {
 			dependencies[i] = strings.Split(depTag, ".")[0]
 		}
 
-		if jsonTag := field.Tag.Get("json"); jsonTag != "" && jsonTag != "-" {
+		if jsonTag := tags.tagFor(field, "body"); jsonTag != "" && jsonTag != "-" {
 			hasJSONBody = true
 		}
 	}
@@ -52,13 +72,24 @@ func getPathVars(r *http.Request) map[string]string {
 }
 
 // extractFields handles field extraction logic (shared between regular and SSE handlers)
-func extractFields(ctx context.Context, reqValue reflect.Value, extractors map[int]FieldExtractor, dependencies map[int]string, r *http.Request, vars map[string]string, body []byte, depResolver *DependencyResolver, resolved *ResolvedDependencies) error {
+func extractFields(ctx context.Context, reqValue reflect.Value, extractors map[int]FieldExtractor, dependencies map[int]string, r *http.Request, vars map[string]string, body []byte, depResolver *DependencyResolver, cfg *routerConfig, resolved *ResolvedDependencies) error {
 	for fieldIdx, extractor := range extractors {
 		var value interface{}
 		var err error
 
 		// Handle dependency extractors
 		if depExt, ok := extractor.(*DependencyExtractor); ok {
+			// Unused alternatives of a RequireAnyOf group keep their zero value
+			if resolved.isSkipped(depExt.depName) {
+				continue
+			}
+
+			// Lazy dependencies are resolved when the handler first calls the field
+			if depExt.lazy {
+				reqValue.Field(fieldIdx).Set(depResolver.lazyDependency(ctx, depExt, r, vars, body, resolved, nil))
+				continue
+			}
+
 			// Resolve the dependency
 			depResult, err := depResolver.Resolve(ctx, depExt.depName, r, vars, body, resolved)
 			if err != nil {
@@ -71,6 +102,18 @@ func extractFields(ctx context.Context, reqValue reflect.Value, extractors map[i
 			} else {
 				value = depResult
 			}
+		} else if streamExt, ok := extractor.(*StreamExtractor); ok {
+			// Streams report record validation failures with the configured status and
+			// share the body size limit
+			value, err = streamExt.open(r, cfg.validationStatus, cfg.maxBodySize)
+			if err != nil {
+				return err
+			}
+		} else if jsonExt, ok := extractor.(*JSONExtractor); ok {
+			value, err = jsonExt.extract(body, cfg.lenientArrays)
+			if err != nil {
+				return err
+			}
 		} else {
 			value, err = extractor.Extract(r, vars, body)
 			if err != nil {
@@ -95,7 +138,7 @@ func extractFields(ctx context.Context, reqValue reflect.Value, extractors map[i
 }
 
 // compileHandler pre-compiles a handler function for efficient execution
-func compileHandler(handler interface{}) (*CompiledHandler, error) {
+func compileHandler(handler interface{}, tags tagMapping) (*CompiledHandler, error) {
 	handlerType := reflect.TypeOf(handler)
 	handlerValue := reflect.ValueOf(handler)
 
@@ -104,8 +147,8 @@ func compileHandler(handler interface{}) (*CompiledHandler, error) {
 		return nil, fmt.Errorf("handler must be a function")
 	}
 
-	if handlerType.NumIn() != 2 || handlerType.NumOut() != 2 {
-		return nil, fmt.Errorf("handler must have signature: func(context.Context, Request) (Response, error)")
+	if handlerType.NumIn() != 2 || handlerType.NumOut() < 1 || handlerType.NumOut() > 2 {
+		return nil, fmt.Errorf("handler must have signature: func(context.Context, Request) (Response, error) or func(context.Context, Request) error")
 	}
 
 	// Verify first param is context.Context
@@ -113,21 +156,26 @@ func compileHandler(handler interface{}) (*CompiledHandler, error) {
 		return nil, fmt.Errorf("first parameter must be context.Context")
 	}
 
-	// Verify second return type is error
-	if handlerType.Out(1) != reflect.TypeOf((*error)(nil)).Elem() {
-		return nil, fmt.Errorf("second return value must be error")
+	// Verify last return type is error
+	if handlerType.Out(handlerType.NumOut()-1) != reflect.TypeOf((*error)(nil)).Elem() {
+		return nil, fmt.Errorf("last return value must be error")
 	}
 
 	reqType := handlerType.In(1)
-	respType := handlerType.Out(0)
 
-	extractors, validators, err := compileStructExtractors(reqType)
+	// Handlers returning only an error respond 204 like those returning NoContent
+	if handlerType.NumOut() == 1 {
+		handlerValue = withNoContentResult(handlerValue)
+	}
+	respType := handlerValue.Type().Out(0)
+
+	extractors, validators, err := compileStructExtractors(reqType, tags)
 	if err != nil {
 		return nil, err
 	}
 
 	// Use shared helper
-	dependencies, hasJSONBody := extractHandlerMetadata(reqType)
+	dependencies, hasJSONBody := extractHandlerMetadata(reqType, tags)
 
 	return &CompiledHandler{
 		handlerFunc:  handlerValue,
@@ -135,13 +183,79 @@ func compileHandler(handler interface{}) (*CompiledHandler, error) {
 		respType:     respType,
 		extractors:   extractors,
 		validators:   validators,
+		fieldSources: compileFieldSources(reqType, extractors),
 		dependencies: dependencies,
 		hasJSONBody:  hasJSONBody,
+		bodyFields:   compileBodyFields(extractors),
 	}, nil
 }
 
+// HeaderSetter is implemented by responses that set response headers alongside
+// their body, e.g. X-Total-Count for paginated lists. The headers replace any set
+// by dependencies under the same names.
+type HeaderSetter interface {
+	Headers() http.Header
+}
+
+// applyResponseHeaders merges the headers of a HeaderSetter response into h
+func applyResponseHeaders(h http.Header, resp interface{}) {
+	setter, ok := resp.(HeaderSetter)
+	if !ok {
+		return
+	}
+	for name, values := range setter.Headers() {
+		h[http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+	}
+}
+
+// Trailerer is implemented by JSON responses that send HTTP trailers after their
+// body, e.g. grpc-status for grpc-web. Trailers is called once the body is written,
+// so it may report values computed while encoding; trailers are sent over chunked
+// HTTP/1.1 and HTTP/2.
+type Trailerer interface {
+	Trailers() http.Header
+}
+
+// writeTrailers sends the trailers of a Trailerer response once the body is written.
+// The body is flushed first so HTTP/1.1 responses are chunked rather than given a
+// Content-Length, then the trailers are set using http.TrailerPrefix.
+func writeTrailers(w http.ResponseWriter, resp interface{}) {
+	trailerer, ok := resp.(Trailerer)
+	if !ok {
+		return
+	}
+	http.NewResponseController(w).Flush()
+	for name, values := range trailerer.Trailers() {
+		w.Header()[http.TrailerPrefix+http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+	}
+}
+
+// statusResponder is implemented by the framework's own response types whose status
+// depends on their content, e.g. a HealthResponse reporting failed checks
+type statusResponder interface {
+	responseStatus() int
+}
+
+// NoContent is a response type for handlers that reply 204 No Content with no body
+type NoContent struct{}
+
+var noContentType = reflect.TypeOf(NoContent{})
+
+// withNoContentResult adapts a func(ctx, req) error handler to return (NoContent, error)
+func withNoContentResult(fn reflect.Value) reflect.Value {
+	fnType := fn.Type()
+	adaptedType := reflect.FuncOf(
+		[]reflect.Type{fnType.In(0), fnType.In(1)},
+		[]reflect.Type{noContentType, fnType.Out(0)},
+		false,
+	)
+	return reflect.MakeFunc(adaptedType, func(args []reflect.Value) []reflect.Value {
+		return []reflect.Value{reflect.ValueOf(NoContent{}), fn.Call(args)[0]}
+	})
+}
+
 // compileStructExtractors creates extractors for all fields in a struct
-func compileStructExtractors(structType reflect.Type) (map[int]FieldExtractor, map[int]string, error) {
+func compileStructExtractors(structType reflect.Type, tags tagMapping) (map[int]FieldExtractor, map[int]string, error) {
 	extractors := make(map[int]FieldExtractor)
 	validators := make(map[int]string)
 
@@ -152,24 +266,53 @@ func compileStructExtractors(structType reflect.Type) (map[int]FieldExtractor, m
 		if field.PkgPath != "" {
 			continue
 		}
+		if err := tags.checkSourceTags(field); err != nil {
+			return nil, nil, err
+		}
 
 		// Handle different tag types
-		if pathTag := field.Tag.Get("path"); pathTag != "" {
+		if sourceTag := field.Tag.Get("source"); sourceTag != "" {
+			sources, err := parseSourceTag(sourceTag)
+			if err != nil {
+				return nil, nil, fmt.Errorf("field %s: %w", field.Name, err)
+			}
+			extractors[i] = &MultiSourceExtractor{
+				sources:   sources,
+				fieldType: field.Type,
+			}
+		} else if _, ok := field.Tag.Lookup("in"); ok {
+			extractor, err := compileQueryStruct(field, tags)
+			if err != nil {
+				return nil, nil, err
+			}
+			extractors[i] = extractor
+		} else if pathTag := tags.tagFor(field, "path"); pathTag != "" {
 			extractors[i] = &PathExtractor{
 				paramName: pathTag,
 				fieldType: field.Type,
 			}
-		} else if queryTag := field.Tag.Get("query"); queryTag != "" {
+		} else if queryTag := tags.tagFor(field, "query"); queryTag != "" {
+			delimiter, err := listDelimiter(field)
+			if err != nil {
+				return nil, nil, err
+			}
 			extractors[i] = &QueryExtractor{
-				paramName: queryTag,
-				fieldType: field.Type,
+				paramName:   queryTag,
+				fieldType:   field.Type,
+				jsonEncoded: isJSONQueryType(field.Type),
+				delimiter:   delimiter,
+			}
+		} else if headerTag := tags.tagFor(field, "header"); headerTag != "" {
+			delimiter, err := listDelimiter(field)
+			if err != nil {
+				return nil, nil, err
 			}
-		} else if headerTag := field.Tag.Get("header"); headerTag != "" {
 			extractors[i] = &HeaderExtractor{
 				headerName: headerTag,
 				fieldType:  field.Type,
+				delimiter:  delimiter,
 			}
-		} else if jsonTag := field.Tag.Get("json"); jsonTag != "" && jsonTag != "-" {
+		} else if jsonTag := tags.tagFor(field, "body"); jsonTag != "" && jsonTag != "-" {
 			jsonPath := strings.Split(jsonTag, ",")[0]
 			extractors[i] = &JSONExtractor{
 				jsonPath:  jsonPath,
@@ -177,10 +320,62 @@ func compileStructExtractors(structType reflect.Type) (map[int]FieldExtractor, m
 			}
 		} else if depTag := field.Tag.Get("dep"); depTag != "" {
 			parts := strings.Split(depTag, ".")
+			lazy := field.Type.Kind() == reflect.Func
+			if lazy && !isLazyDependencyType(field.Type) {
+				return nil, nil, fmt.Errorf("field %s with dep tag must be a value or a func() (T, error)", field.Name)
+			}
 			extractors[i] = &DependencyExtractor{
 				depName:   parts[0],
 				fieldPath: parts,
 				fieldType: field.Type,
+				lazy:      lazy,
+			}
+		} else if _, ok := field.Tag.Lookup("basicauth"); ok {
+			if field.Type != reflect.TypeOf(BasicCredentials{}) {
+				return nil, nil, fmt.Errorf("field %s with basicauth tag must be of type BasicCredentials", field.Name)
+			}
+			extractors[i] = &BasicAuthExtractor{
+				fieldType: field.Type,
+			}
+		} else if _, ok := field.Tag.Lookup("requestid"); ok {
+			if field.Type.Kind() != reflect.String {
+				return nil, nil, fmt.Errorf("field %s with requestid tag must be a string", field.Name)
+			}
+			extractors[i] = &RequestIDExtractor{
+				fieldType: field.Type,
+			}
+		} else if _, ok := field.Tag.Lookup("pathparams"); ok {
+			if field.Type != reflect.TypeOf(map[string]string{}) {
+				return nil, nil, fmt.Errorf("field %s with pathparams tag must be a map[string]string", field.Name)
+			}
+			extractors[i] = &PathParamsExtractor{
+				fieldType: field.Type,
+			}
+		} else if _, ok := field.Tag.Lookup("routepattern"); ok {
+			if field.Type.Kind() != reflect.String {
+				return nil, nil, fmt.Errorf("field %s with routepattern tag must be a string", field.Name)
+			}
+			extractors[i] = &RoutePatternExtractor{
+				fieldType: field.Type,
+			}
+		} else if _, ok := field.Tag.Lookup("request"); ok {
+			if field.Type != reflect.TypeOf((*http.Request)(nil)) {
+				return nil, nil, fmt.Errorf("field %s with request tag must be a *http.Request", field.Name)
+			}
+			extractors[i] = &RequestExtractor{
+				fieldType: field.Type,
+			}
+		} else if _, ok := field.Tag.Lookup("stream"); ok {
+			if !isRecordStreamType(field.Type) {
+				return nil, nil, fmt.Errorf("field %s with stream tag must be a *RecordStream[T]", field.Name)
+			}
+			if recordType := transformElem(streamRecordType(field.Type)); recordType.Kind() == reflect.Struct {
+				if _, err := compileTransforms(recordType); err != nil {
+					return nil, nil, fmt.Errorf("field %s: %w", field.Name, err)
+				}
+			}
+			extractors[i] = &StreamExtractor{
+				fieldType: field.Type,
 			}
 		}
 
@@ -190,21 +385,329 @@ func compileStructExtractors(structType reflect.Type) (map[int]FieldExtractor, m
 		}
 	}
 
+	if err := checkBodyConsumers(extractors); err != nil {
+		return nil, nil, err
+	}
+	if err := checkDuplicateSources(structType, extractors); err != nil {
+		return nil, nil, err
+	}
+	if _, err := compileTransforms(structType); err != nil {
+		return nil, nil, err
+	}
+
 	return extractors, validators, nil
 }
 
+// sourceTags are the tags, besides those of a tagMapping, that bind a field to a
+// request source. Tags whose value names the source count when non-empty; marker
+// tags count when present.
+var sourceTags = []struct {
+	name   string
+	marker bool
+}{
+	{"source", false}, {"dep", false},
+	{"in", true}, {"basicauth", true}, {"requestid", true}, {"pathparams", true}, {"routepattern", true}, {"request", true}, {"stream", true},
+}
+
+// tagMapping maps the path, query, header and body sources to the struct tags that
+// bind fields to them; nil means the default tags. A mapping is never modified, so
+// SetTagMapping replaces the router's with an updated copy.
+type tagMapping map[string]string
+
+var defaultTagMapping = tagMapping{"path": "path", "query": "query", "header": "header", "body": "json"}
+
+// SetTagMapping binds request struct fields to a source by another struct tag, so
+// existing structs can be reused without re-annotating them, e.g.
+// r.SetTagMapping("body", "db") reads body fields by their db tags instead of their
+// json tags. source is path, query, header or body. The mapping applies to routes,
+// controllers and dependencies registered on the router afterwards.
+func (r *Router) SetTagMapping(source, tag string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tags, err := r.tags.with(source, tag)
+	if err != nil {
+		return err
+	}
+	r.tags = tags
+	r.openAPIBuilder.setTagMapping(tags)
+	return nil
+}
+
+// tagMapping returns the router's current tag mapping
+func (r *Router) tagMapping() tagMapping {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.tags
+}
+
+// with returns a copy of m that binds fields to source by tag
+func (m tagMapping) with(source, tag string) (tagMapping, error) {
+	if m == nil {
+		m = defaultTagMapping
+	}
+	if _, ok := m[source]; !ok {
+		return nil, fmt.Errorf("unknown request source %q; must be path, query, header or body", source)
+	}
+	if tag == "" {
+		return nil, fmt.Errorf("tag name for source %s must not be empty", source)
+	}
+	for other, name := range m {
+		if other != source && name == tag {
+			return nil, fmt.Errorf("tag %q already binds fields to source %s", tag, other)
+		}
+	}
+	for _, reserved := range sourceTags {
+		if reserved.name == tag {
+			return nil, fmt.Errorf("tag %q is reserved", tag)
+		}
+	}
+	updated := maps.Clone(m)
+	updated[source] = tag
+	return updated, nil
+}
+
+// tagName returns the name of the struct tag that binds fields to source
+func (m tagMapping) tagName(source string) string {
+	if m == nil {
+		m = defaultTagMapping
+	}
+	return m[source]
+}
+
+// tagFor returns the value of the struct tag that binds field to source, e.g. the
+// query tag for "query" unless it has been remapped
+func (m tagMapping) tagFor(field reflect.StructField, source string) string {
+	return field.Tag.Get(m.tagName(source))
+}
+
+// checkSourceTags rejects fields bound to more than one request source, e.g. both
+// path and query, which would otherwise silently use the first in precedence order
+func (m tagMapping) checkSourceTags(field reflect.StructField) error {
+	var found []string
+	for _, source := range []string{"path", "query", "header", "body"} {
+		if value := m.tagFor(field, source); value != "" && value != "-" {
+			found = append(found, m.tagName(source))
+		}
+	}
+	for _, tag := range sourceTags {
+		value, ok := field.Tag.Lookup(tag.name)
+		if tag.marker && ok || !tag.marker && value != "" && value != "-" {
+			found = append(found, tag.name)
+		}
+	}
+	if len(found) > 1 {
+		return fmt.Errorf("field %s has conflicting source tags %s; use source:\"...\" to read from several locations",
+			field.Name, strings.Join(found, " and "))
+	}
+	return nil
+}
+
+// checkDuplicateSources rejects structs with two fields bound to the same path, query
+// or header parameter or body key. Header names are compared case-insensitively.
+func checkDuplicateSources(structType reflect.Type, extractors map[int]FieldExtractor) error {
+	bound := make(map[string]string)
+	for _, field := range flattenExtractors(structType, extractors) {
+		var source string
+		switch e := field.extractor.(type) {
+		case *PathExtractor, *QueryExtractor, *JSONExtractor:
+			source = extractorSource(e)
+		case *HeaderExtractor:
+			source = "header." + http.CanonicalHeaderKey(e.headerName)
+		default:
+			continue
+		}
+		if other, ok := bound[source]; ok {
+			return fmt.Errorf("fields %s and %s are both bound to %s", other, field.name, source)
+		}
+		bound[source] = field.name
+	}
+	return nil
+}
+
+// checkBodylessFields rejects GET and HEAD request structs with a body field named like
+// one of their path or query parameters, which clients can't tell apart from it
+func checkBodylessFields(method string, structType reflect.Type, extractors map[int]FieldExtractor) error {
+	if method != http.MethodGet && method != http.MethodHead {
+		return nil
+	}
+	params := make(map[string]string)
+	for _, field := range flattenExtractors(structType, extractors) {
+		switch e := field.extractor.(type) {
+		case *PathExtractor:
+			params[e.paramName] = extractorSource(e)
+		case *QueryExtractor:
+			params[e.paramName] = extractorSource(e)
+		}
+	}
+	for fieldIdx := 0; fieldIdx < structType.NumField(); fieldIdx++ {
+		if e, ok := extractors[fieldIdx].(*JSONExtractor); ok {
+			if param, conflict := params[strings.Split(e.jsonPath, ".")[0]]; conflict {
+				return fmt.Errorf("field %s reads body.%s on a %s route, which conflicts with %s; %s requests have no body",
+					structType.Field(fieldIdx).Name, e.jsonPath, method, param, method)
+			}
+		}
+	}
+	return nil
+}
+
+// routeTemplateParams returns the variables of a route template in order, e.g. ["id"]
+// for /users/{id:[0-9]+}
+func routeTemplateParams(path string) []string {
+	var params []string
+	depth, start := 0, 0
+	for i, c := range path {
+		switch c {
+		case '{':
+			if depth == 0 {
+				start = i + 1
+			}
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				name, _, _ := strings.Cut(path[start:i], ":")
+				params = append(params, name)
+			}
+		}
+	}
+	return params
+}
+
+// pathParamReads are the path parameters read by a request struct or its dependencies
+type pathParamReads struct {
+	names map[string]bool
+	all   bool // A pathparams map or *http.Request field can read any parameter
+}
+
+// add records the path parameters read by extractor
+func (p *pathParamReads) add(extractor FieldExtractor) {
+	switch e := extractor.(type) {
+	case *PathExtractor:
+		p.names[e.paramName] = true
+	case *MultiSourceExtractor:
+		for _, source := range e.sources {
+			if source.in == "path" {
+				p.names[source.name] = true
+			}
+		}
+	case *PathParamsExtractor, *RequestExtractor:
+		p.all = true
+	}
+}
+
+// checkPathParams cross-checks the path parameters of a request struct against the
+// route template: every path-tagged field must name a template variable, and every
+// variable must be read by the struct, a source fallback or deps, the path
+// parameters read by the route's dependencies
+func checkPathParams(path string, structType reflect.Type, extractors map[int]FieldExtractor, deps *pathParamReads) error {
+	declared := make(map[string]bool)
+	for _, param := range routeTemplateParams(path) {
+		declared[param] = true
+	}
+
+	read := &pathParamReads{names: make(map[string]bool), all: deps.all}
+	for param := range deps.names {
+		read.names[param] = true
+	}
+	for fieldIdx := 0; fieldIdx < structType.NumField(); fieldIdx++ {
+		if e, ok := extractors[fieldIdx].(*PathExtractor); ok && !declared[e.paramName] {
+			return fmt.Errorf("field %s reads path parameter %q, but the route template %s has no {%s}",
+				structType.Field(fieldIdx).Name, e.paramName, path, e.paramName)
+		}
+		read.add(extractors[fieldIdx])
+	}
+	if read.all {
+		return nil
+	}
+
+	for _, param := range routeTemplateParams(path) {
+		if !read.names[param] {
+			return fmt.Errorf("route template %s declares {%s}, but no field of the request struct or its dependencies has path:%q",
+				path, param, param)
+		}
+	}
+	return nil
+}
+
+// checkBodyConsumers rejects structs that would read the body both as a stream and as JSON
+func checkBodyConsumers(extractors map[int]FieldExtractor) error {
+	streams, jsonFields := 0, 0
+	for _, extractor := range extractors {
+		switch extractor.(type) {
+		case *StreamExtractor:
+			streams++
+		case *JSONExtractor:
+			jsonFields++
+		}
+	}
+	if streams > 1 || (streams == 1 && jsonFields > 0) {
+		return fmt.Errorf("a stream field must be the only consumer of the request body")
+	}
+	return nil
+}
+
+// checkContentType rejects JSON bodies sent with a Content-Type other than the route's
+// declared media type. Requests without a Content-Type are accepted and parsed as JSON.
+func checkContentType(r *http.Request, hasJSONBody bool, contentType string) error {
+	header := r.Header.Get("Content-Type")
+	if !hasJSONBody || contentType == "" || header == "" {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil || !strings.EqualFold(mediaType, contentType) {
+		return NewErrorWithCode(http.StatusUnsupportedMediaType, "UNSUPPORTED_MEDIA_TYPE", fmt.Sprintf("Content-Type must be %s", contentType))
+	}
+	return nil
+}
+
+// recoverPanic recovers a panic in a handler, dependency or hook, logging it with the
+// request's attributes and answering with a 500 through errorHandler.
+// http.ErrAbortHandler is re-raised, as net/http expects.
+func recoverPanic(w http.ResponseWriter, r *http.Request, logger *slog.Logger, errorHandler ErrorHandler) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+	if recovered == http.ErrAbortHandler {
+		panic(recovered)
+	}
+	logger.Error("panic serving request", requestAttrs(r, "panic", recovered, "stack", string(debug.Stack()))...)
+	errorHandler(w, r, NewErrorWithCode(http.StatusInternalServerError, "INTERNAL_ERROR", "An internal error occurred"))
+}
+
 // Execute runs the compiled handler
 func (ch *CompiledHandler) Execute(ctx context.Context, w http.ResponseWriter, r *http.Request, depResolver *DependencyResolver, errorHandler ErrorHandler) {
+	cfg := ch.config.Load()
+	defer recoverPanic(w, r, cfg.log(), errorHandler)
+	if ch.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ch.timeout)
+		defer cancel()
+	}
+
+	if err := checkContentType(r, ch.hasJSONBody, ch.contentType); err != nil {
+		errorHandler(w, r, err)
+		return
+	}
+
 	// Read body once if needed
 	var body []byte
 	var err error
 	if ch.hasJSONBody && r.Body != nil {
-		body, err = io.ReadAll(r.Body)
+		defer r.Body.Close()
+		body, err = readBody(r, cfg.maxBodySize)
 		if err != nil {
-			errorHandler(w, r, fmt.Errorf("failed to read request body: %w", err))
+			errorHandler(w, r, err)
+			return
+		}
+	}
+
+	// Reject unknown body fields in strict mode, before any dependency runs
+	if ch.strictBody {
+		if unknown := findUnknownBodyFields(body, ch.bodyFields); len(unknown) > 0 {
+			errorHandler(w, r, NewValidationErrorWithStatus(cfg.validationStatus, unknown))
 			return
 		}
-		defer r.Body.Close()
 	}
 
 	// Extract path variables
@@ -218,35 +721,115 @@ func (ch *CompiledHandler) Execute(ctx context.Context, w http.ResponseWriter, r
 		values: make(map[string]interface{}),
 	}
 
+	// Apply dependency headers before writing any response
+	fail := func(err error) {
+		resolved.applyHeaders(w.Header(), err)
+		errorHandler(w, r, err)
+	}
+
+	// Resolve RequireAnyOf groups before the fields that may reference them
+	if err := depResolver.resolveAnyOf(ctx, ch.anyOf, r, vars, body, resolved); err != nil {
+		fail(err)
+		return
+	}
+
 	// Extract all fields using shared logic
-	err = extractFields(ctx, reqValue, ch.extractors, ch.dependencies, r, vars, body, depResolver, resolved)
+	err = extractFields(ctx, reqValue, ch.extractors, ch.dependencies, r, vars, body, depResolver, cfg, resolved)
 	if err != nil {
-		errorHandler(w, r, err)
+		fail(err)
+		return
+	}
+
+	// Normalize tagged string fields before they are validated
+	if err := applyTransforms(reqValue); err != nil {
+		fail(err)
 		return
 	}
 
 	// Validate the request
-	if err := validateStruct(reqValue.Interface(), ch.validators); err != nil {
-		errorHandler(w, r, err)
+	if err := validateStruct(reqValue.Interface(), ch.fieldSources, cfg.validationStatus, translatorFor(r)); err != nil {
+		fail(err)
 		return
 	}
 
-	// Call the handler
-	results := ch.handlerFunc.Call([]reflect.Value{
-		reflect.ValueOf(ctx),
-		reqValue,
+	// Let request hooks see the validated request
+	if err := ch.hooks.beforeHandler(ctx, reqValue.Interface()); err != nil {
+		fail(err)
+		return
+	}
+
+	// Call the handler through the interceptors, which may modify the request
+	resp, err := ch.hooks.intercept(ctx, reqValue.Addr().Interface(), ch.interceptors, func() (interface{}, error) {
+		results := ch.handlerFunc.Call([]reflect.Value{
+			reflect.ValueOf(ctx),
+			reqValue,
+		})
+		if !results[1].IsNil() {
+			return nil, results[1].Interface().(error)
+		}
+		// A handler ignoring ctx may return after the route timeout; it still fails
+		if ch.timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, ctx.Err()
+		}
+		return results[0].Interface(), nil
 	})
 
 	// Handle error response
-	if !results[1].IsNil() {
-		errorHandler(w, r, results[1].Interface().(error))
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	// Let response hooks inspect or reshape the response
+	resp, err = ch.hooks.afterHandler(ctx, resp)
+	if err != nil {
+		fail(err)
 		return
 	}
 
 	// Serialize response
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(results[0].Interface()); err != nil {
-		fmt.Printf("Failed to encode response: %v\n", err)
+	resolved.applyHeaders(w.Header(), nil)
+	applyResponseHeaders(w.Header(), resp)
+	applyPageLinks(w.Header(), r, resp, cfg.pageParam)
+	// Response hooks may replace the value, so go by what was actually returned
+	respType := ch.respType
+	if resp != nil {
+		respType = reflect.TypeOf(resp)
+	}
+	if respType == noContentType {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if isFileStreamType(respType) {
+		if err := serveFileStream(w, r, resp); err != nil {
+			errorHandler(w, r, err)
+		}
+		return
+	}
+	if isStreamType(respType) {
+		serveStream(w, r, resp, errorHandler, cfg.log())
+		return
+	}
+	if writeConditionalHeaders(w, r, resp) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	trailerer := resp
+	status := http.StatusOK
+	if responder, ok := resp.(statusResponder); ok {
+		status = responder.responseStatus()
+	}
+	if envelope := cfg.responseEnvelope; envelope != nil {
+		resp = envelope(resp)
+	}
+	contentType := "application/json"
+	if ch.responseContentType != "" {
+		contentType = ch.responseContentType
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		cfg.log().Error("failed to encode response", requestAttrs(r, "error", err)...)
 	}
+	writeTrailers(w, trailerer)
 }
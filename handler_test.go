@@ -0,0 +1,266 @@
+package gofastapi
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type grpcWebResponse struct {
+	Message string `json:"message"`
+}
+
+func (grpcWebResponse) Trailers() http.Header {
+	return http.Header{"grpc-status": {"0"}}
+}
+
+func TestTrailers(t *testing.T) {
+	r := New()
+	err := r.GET("/echo", func(ctx context.Context, req struct{}) (grpcWebResponse, error) {
+		return grpcWebResponse{Message: "hi"}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	http1 := httptest.NewServer(r)
+	defer http1.Close()
+	http2 := httptest.NewUnstartedServer(r)
+	http2.EnableHTTP2 = true
+	http2.StartTLS()
+	defer http2.Close()
+
+	servers := []struct {
+		name       string
+		server     *httptest.Server
+		protoMajor int
+	}{{"HTTP/1.1", http1, 1}, {"HTTP/2", http2, 2}}
+	for _, s := range servers {
+		t.Run(s.name, func(t *testing.T) {
+			resp, err := s.server.Client().Get(s.server.URL + "/echo")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.ProtoMajor != s.protoMajor {
+				t.Fatalf("protocol = %s, want %s", resp.Proto, s.name)
+			}
+			// Trailers are only populated once the body has been read
+			if _, err := io.ReadAll(resp.Body); err != nil {
+				t.Fatal(err)
+			}
+			if got := resp.Trailer.Get("Grpc-Status"); got != "0" {
+				t.Errorf("Grpc-Status trailer = %q, want 0 (trailers: %v)", got, resp.Trailer)
+			}
+		})
+	}
+}
+
+type strictItem struct {
+	Name string `json:"name"`
+}
+
+type strictAddress struct {
+	City string `json:"city"`
+}
+
+func TestStrictBodyReportsEveryUnknownField(t *testing.T) {
+	var depCalls int
+	r := New()
+	err := RegisterDependency(r, "user", func(ctx context.Context, req struct{}) (string, error) {
+		depCalls++
+		return "user", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.POST("/orders", func(ctx context.Context, req struct {
+		User    string        `dep:"user"`
+		Items   []strictItem  `json:"items"`
+		Address strictAddress `json:"address"`
+	}) (string, error) {
+		return req.User, nil
+	}, WithStrictBody())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := `{"items":[{"name":"a"},{"name":"b","nmae":"c"}],"address":{"city":"x","zip":"1","extra":{}},"note":""}`
+	resp, err := r.TestRequest(http.MethodPost, "/orders", []byte(body), WithTestHeader("Content-Type", "application/json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400; body: %s", resp.StatusCode, resp.Body)
+	}
+	var errResp ErrorResponse
+	if err := resp.DecodeJSON(&errResp); err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{"body.items[1].nmae", "body.address.zip", "body.address.extra", "body.note"} {
+		if _, ok := errResp.Fields[field]; !ok {
+			t.Errorf("fields = %v, want %s reported", errResp.Fields, field)
+		}
+	}
+	if len(errResp.Fields) != 4 {
+		t.Errorf("fields = %v, want exactly 4 unknown fields", errResp.Fields)
+	}
+	if depCalls != 0 {
+		t.Errorf("dependency ran %d times, want strict mode to reject the body first", depCalls)
+	}
+}
+
+func TestHandlerPanicIsRecovered(t *testing.T) {
+	var logs bytes.Buffer
+	r := New()
+	r.SetLogger(slog.New(slog.NewTextHandler(&logs, nil)))
+	err := r.GET("/boom", func(ctx context.Context, req struct{}) (string, error) {
+		panic("boom")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := r.TestRequest(http.MethodGet, "/boom", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", resp.StatusCode)
+	}
+	var body ErrorResponse
+	if err := resp.DecodeJSON(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Code != "INTERNAL_ERROR" {
+		t.Errorf("code = %q, want INTERNAL_ERROR", body.Code)
+	}
+	for _, want := range []string{"panic serving request", "path=/boom", "panic=boom"} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("log %q does not contain %q", logs.String(), want)
+		}
+	}
+}
+
+func TestResponseHookReplacesResponseKind(t *testing.T) {
+	r := New()
+	r.OnResponse(func(ctx context.Context, resp interface{}) (interface{}, error) {
+		switch resp.(type) {
+		case string:
+			return NoContent{}, nil
+		case NoContent:
+			return map[string]string{"status": "deleted"}, nil
+		}
+		return resp, nil
+	})
+	err := r.GET("/text", func(ctx context.Context, req struct{}) (string, error) {
+		return "hi", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.DELETE("/items", func(ctx context.Context, req struct{}) (NoContent, error) {
+		return NoContent{}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := r.TestRequest(http.MethodGet, "/text", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusNoContent || len(resp.Body) != 0 {
+		t.Errorf("NoContent from hook: status = %d, body = %q, want 204 and no body", resp.StatusCode, resp.Body)
+	}
+
+	resp, err = r.TestRequest(http.MethodDelete, "/items", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body map[string]string
+	if err := resp.DecodeJSON(&body); err != nil {
+		t.Fatalf("decode %q: %v", resp.Body, err)
+	}
+	if resp.StatusCode != http.StatusOK || body["status"] != "deleted" {
+		t.Errorf("JSON from hook: status = %d, body = %q, want 200 with the hook's value", resp.StatusCode, resp.Body)
+	}
+}
+
+func TestRouteTimeoutCallsHandlerSynchronously(t *testing.T) {
+	var finished atomic.Bool
+	r := New()
+	err := r.GET("/slow", func(ctx context.Context, req struct{}) (string, error) {
+		time.Sleep(50 * time.Millisecond) // Ignores ctx
+		finished.Store(true)
+		return "late", nil
+	}, WithTimeout(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := r.TestRequest(http.MethodGet, "/slow", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !finished.Load() {
+		t.Error("request answered while the handler was still running")
+	}
+	var body ErrorResponse
+	if err := resp.DecodeJSON(&body); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusGatewayTimeout || body.Code != "TIMEOUT" {
+		t.Errorf("status = %d, code = %q, want 504 TIMEOUT for a handler returning after the deadline", resp.StatusCode, body.Code)
+	}
+}
+
+func TestSetTagMappingIsPerRouter(t *testing.T) {
+	type searchRequest struct {
+		Term string `form:"term"`
+	}
+	handler := func(ctx context.Context, req searchRequest) (string, error) {
+		return req.Term, nil
+	}
+
+	mapped, plain := New(), New()
+	if err := mapped.SetTagMapping("query", "form"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mapped.SetTagMapping("query", "path"); err == nil {
+		t.Error("mapping query to the path tag succeeded, want an error")
+	}
+	for _, r := range []*Router{mapped, plain} {
+		if err := r.GET("/search", handler); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tt := range []struct {
+		name string
+		r    *Router
+		want string
+	}{
+		{"mapped", mapped, "go"},
+		{"plain", plain, ""},
+	} {
+		resp, err := tt.r.TestRequest(http.MethodGet, "/search?term=go", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got string
+		if err := resp.DecodeJSON(&got); err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("%s router: term = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package gofastapi
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// HealthCheck checks a service the application depends on, e.g. by pinging a database
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// HealthResponse is the body of health check endpoints
+type HealthResponse struct {
+	Status   string            `json:"status" description:"Overall status" example:"healthy"`
+	Services map[string]string `json:"services,omitempty" description:"Status of each checked service"`
+	Errors   map[string]string `json:"errors,omitempty" description:"Error of each failing service"`
+}
+
+// responseStatus answers 503 unless every check passed
+func (h HealthResponse) responseStatus() int {
+	if h.Status != "healthy" {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusOK
+}
+
+// AddHealthCheck registers a readiness endpoint at path. It runs checks concurrently
+// and responds with each service's status: 200 when all pass, or 503 with status
+// "unhealthy" and the failing checks' errors. While the router is marked not ready
+// with SetReady(false), it responds 503 NOT_READY without running the checks.
+// The endpoint skips dependencies required with RequireDependencies, e.g. auth.
+func (r *Router) AddHealthCheck(path string, checks ...HealthCheck) error {
+	handler := func(ctx context.Context, req struct{}) (HealthResponse, error) {
+		if r.notReady.Load() {
+			return HealthResponse{}, NewErrorWithCode(http.StatusServiceUnavailable, "NOT_READY", "Service is not ready")
+		}
+		return runHealthChecks(ctx, checks)
+	}
+	return r.GET(path, handler, skipRequiredDependencies(), WithSummary("Readiness check"), WithTags("health"))
+}
+
+// AddLivenessCheck registers a liveness endpoint at path, which responds 200 while the
+// process can serve requests. Unlike readiness, it runs no checks, so an unavailable
+// database doesn't get the process restarted.
+func (r *Router) AddLivenessCheck(path string) error {
+	handler := func(ctx context.Context, req struct{}) (HealthResponse, error) {
+		return HealthResponse{Status: "healthy"}, nil
+	}
+	return r.GET(path, handler, skipRequiredDependencies(), WithSummary("Liveness check"), WithTags("health"))
+}
+
+// SetReady marks the router as ready or not ready to receive traffic, e.g. not ready
+// while draining connections during shutdown. Routers start ready.
+func (r *Router) SetReady(ready bool) {
+	r.notReady.Store(!ready)
+}
+
+// skipRequiredDependencies exempts a route from every dependency required with
+// RequireDependencies, as they stand when the route's requirements are resolved
+func skipRequiredDependencies() RouteOption {
+	return func(c *routeConfig) {
+		c.skipRequired = true
+	}
+}
+
+// runHealthChecks runs checks concurrently and aggregates their results
+func runHealthChecks(ctx context.Context, checks []HealthCheck) (HealthResponse, error) {
+	services := make(map[string]string, len(checks))
+	failures := make(map[string]string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := check.Check(ctx)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				services[check.Name] = "unhealthy"
+				failures[check.Name] = err.Error()
+			} else {
+				services[check.Name] = "healthy"
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(failures) > 0 {
+		return HealthResponse{Status: "unhealthy", Services: services, Errors: failures}, nil
+	}
+	return HealthResponse{Status: "healthy", Services: services}, nil
+}
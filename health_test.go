@@ -0,0 +1,44 @@
+package gofastapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestHealthCheckReportsServices(t *testing.T) {
+	r := New()
+	err := RegisterDependency(r, "auth", func(ctx context.Context, req struct{}) (string, error) {
+		return "", NewError(http.StatusUnauthorized, "unauthorized")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.RequireDependencies("auth")
+	err = r.AddHealthCheck("/ready",
+		HealthCheck{Name: "database", Check: func(ctx context.Context) error { return nil }},
+		HealthCheck{Name: "cache", Check: func(ctx context.Context) error { return errors.New("connection refused") }},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := r.TestRequest(http.MethodGet, "/ready", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503; body: %s", resp.StatusCode, resp.Body)
+	}
+	var body HealthResponse
+	if err := resp.DecodeJSON(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Status != "unhealthy" || body.Services["database"] != "healthy" || body.Services["cache"] != "unhealthy" {
+		t.Errorf("body = %+v, want each service's status", body)
+	}
+	if body.Errors["cache"] != "connection refused" {
+		t.Errorf("errors = %v, want the cache check's error", body.Errors)
+	}
+}
@@ -0,0 +1,117 @@
+package gofastapi
+
+import (
+	"context"
+	"sync"
+)
+
+// RequestHook runs with the decoded and validated request struct before the handler,
+// e.g. to audit payloads. Returning an error aborts the request with that error.
+type RequestHook func(ctx context.Context, req interface{}) error
+
+// ResponseHook runs with the handler's typed response before it is written and
+// returns the response to write, either resp itself or a reshaped value. Returning
+// an error responds with that error instead.
+type ResponseHook func(ctx context.Context, resp interface{}) (interface{}, error)
+
+// Interceptor wraps the call of a route's handler, like a decorator with access to
+// typed values. req is a pointer to the validated request struct, which the
+// interceptor may modify before calling next; next calls the remaining interceptors
+// and the handler and returns the typed response. The interceptor returns the
+// response to write, e.g. next's result, a transformed value or a cached one
+// without calling next at all.
+type Interceptor func(ctx context.Context, req interface{}, next func() (interface{}, error)) (interface{}, error)
+
+// lifecycleHooks holds the hooks shared by all routes of a router
+type lifecycleHooks struct {
+	request      []RequestHook
+	response     []ResponseHook
+	interceptors []Interceptor
+	mu           sync.RWMutex
+}
+
+// OnRequest adds a hook receiving each typed request after validation, for regular
+// and SSE routes. Hooks run in the order added.
+func (r *Router) OnRequest(hook RequestHook) {
+	r.hooks.mu.Lock()
+	defer r.hooks.mu.Unlock()
+	r.hooks.request = append(r.hooks.request, hook)
+}
+
+// OnResponse adds a hook receiving each typed response of regular routes after the
+// handler succeeds. Hooks run in the order added, each receiving the previous result.
+func (r *Router) OnResponse(hook ResponseHook) {
+	r.hooks.mu.Lock()
+	defer r.hooks.mu.Unlock()
+	r.hooks.response = append(r.hooks.response, hook)
+}
+
+// Intercept adds an interceptor around the handlers of all regular routes. Router
+// interceptors run in the order added, outside those added with WithInterceptor.
+func (r *Router) Intercept(interceptor Interceptor) {
+	r.hooks.mu.Lock()
+	defer r.hooks.mu.Unlock()
+	r.hooks.interceptors = append(r.hooks.interceptors, interceptor)
+}
+
+// WithInterceptor adds interceptors around the handler of the route being registered
+func WithInterceptor(interceptors ...Interceptor) RouteOption {
+	return func(cfg *routeConfig) {
+		cfg.interceptors = append(cfg.interceptors, interceptors...)
+	}
+}
+
+// beforeHandler runs the request hooks
+func (h *lifecycleHooks) beforeHandler(ctx context.Context, req interface{}) error {
+	if h == nil {
+		return nil
+	}
+	h.mu.RLock()
+	hooks := h.request
+	h.mu.RUnlock()
+
+	for _, hook := range hooks {
+		if err := hook(ctx, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// afterHandler runs the response hooks, returning the response to write
+func (h *lifecycleHooks) afterHandler(ctx context.Context, resp interface{}) (interface{}, error) {
+	if h == nil {
+		return resp, nil
+	}
+	h.mu.RLock()
+	hooks := h.response
+	h.mu.RUnlock()
+
+	for _, hook := range hooks {
+		var err error
+		if resp, err = hook(ctx, resp); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// intercept calls handler through the router's interceptors followed by route's
+func (h *lifecycleHooks) intercept(ctx context.Context, req interface{}, route []Interceptor, handler func() (interface{}, error)) (interface{}, error) {
+	var chain []Interceptor
+	if h != nil {
+		h.mu.RLock()
+		chain = append(chain, h.interceptors...)
+		h.mu.RUnlock()
+	}
+	chain = append(chain, route...)
+
+	var next func(i int) (interface{}, error)
+	next = func(i int) (interface{}, error) {
+		if i == len(chain) {
+			return handler()
+		}
+		return chain[i](ctx, req, func() (interface{}, error) { return next(i + 1) })
+	}
+	return next(0)
+}
@@ -0,0 +1,112 @@
+package gofastapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/locales"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+)
+
+// TranslationRegisterFunc registers validation messages for a locale, e.g.
+// RegisterDefaultTranslations from a validator/v10/translations/<lang> package
+type TranslationRegisterFunc func(v *validator.Validate, trans ut.Translator) error
+
+var (
+	translators   = make(map[string]ut.Translator) // Lowercase locale name -> translator
+	defaultLocale string                           // Locale used when none is negotiated; "" for none
+	translatorMu  sync.RWMutex
+)
+
+// RegisterLocale enables localized validation messages. The locale is picked from the
+// request's Accept-Language header; requests matching no registered locale keep the
+// untranslated messages, unless SetDefaultLocale names one. Messages set with
+// RegisterValidationMessage take precedence. Locales are process-wide and apply to
+// every router.
+//
+//	gofastapi.RegisterLocale(fr.New(), frTranslations.RegisterDefaultTranslations)
+func RegisterLocale(locale locales.Translator, register TranslationRegisterFunc) error {
+	translatorMu.Lock()
+	defer translatorMu.Unlock()
+	name := strings.ToLower(locale.Locale())
+	trans, found := translators[name]
+	if !found {
+		trans, _ = ut.New(locale, locale).GetTranslator(locale.Locale())
+	}
+	if err := register(getValidator(), trans); err != nil {
+		return fmt.Errorf("register %s validation messages: %w", locale.Locale(), err)
+	}
+	translators[name] = trans
+	return nil
+}
+
+// SetDefaultLocale localizes the validation messages of requests whose Accept-Language
+// header matches no registered locale, e.g. "en" after registering English. The
+// locale must be registered; "" restores the untranslated messages.
+func SetDefaultLocale(locale string) error {
+	translatorMu.Lock()
+	defer translatorMu.Unlock()
+	name := strings.ToLower(strings.ReplaceAll(locale, "-", "_"))
+	if _, found := translators[name]; name != "" && !found {
+		return fmt.Errorf("locale %q is not registered", locale)
+	}
+	defaultLocale = name
+	return nil
+}
+
+// translatorFor returns the translator of the registered locale the request's
+// Accept-Language header prefers, the default locale's, or nil to keep the
+// untranslated messages
+func translatorFor(r *http.Request) ut.Translator {
+	translatorMu.RLock()
+	defer translatorMu.RUnlock()
+	for _, name := range acceptedLocales(r.Header.Get("Accept-Language")) {
+		if trans, found := translators[strings.ToLower(name)]; found {
+			return trans
+		}
+	}
+	return translators[defaultLocale]
+}
+
+// acceptedLocales parses an Accept-Language header into locale names ordered by
+// preference, e.g. "fr-CA;q=0.8, de" yields ["de", "fr_CA", "fr"]. Tags with q=0
+// are not acceptable and are dropped.
+func acceptedLocales(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		tags = append(tags, weighted{tag, q})
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	var names []string
+	for _, t := range tags {
+		name := strings.ReplaceAll(t.tag, "-", "_")
+		names = append(names, name)
+		if base, _, ok := strings.Cut(name, "_"); ok {
+			names = append(names, base)
+		}
+	}
+	return names
+}
@@ -0,0 +1,91 @@
+package gofastapi
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/locales/en"
+	ut "github.com/go-playground/universal-translator"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+)
+
+func TestAcceptedLocales(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []string
+	}{
+		{"fr-CA;q=0.8, de", []string{"de", "fr_CA", "fr"}},
+		{"fr;q=0, de;q=0.5", []string{"de"}},
+		{"fr;q=0.0", nil},
+		{"*", nil},
+	}
+	for _, tt := range tests {
+		if got := acceptedLocales(tt.header); !slices.Equal(got, tt.want) {
+			t.Errorf("acceptedLocales(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+type adultsRequest struct {
+	Age int `query:"age" validate:"min=18"`
+}
+
+func TestValidationMessageLocales(t *testing.T) {
+	t.Cleanup(func() {
+		translatorMu.Lock()
+		defer translatorMu.Unlock()
+		translators = make(map[string]ut.Translator)
+		defaultLocale = ""
+	})
+
+	r := New()
+	err := r.GET("/users", func(ctx context.Context, req adultsRequest) (string, error) {
+		return "ok", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := func(t *testing.T, acceptLanguage string) string {
+		t.Helper()
+		resp, err := r.TestRequest(http.MethodGet, "/users?age=3", nil, WithTestHeader("Accept-Language", acceptLanguage))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var body ErrorResponse
+		if err := resp.DecodeJSON(&body); err != nil {
+			t.Fatal(err)
+		}
+		return strings.Join(body.Fields["query.age"], "; ")
+	}
+
+	const untranslated, english = "failed min validation", "Age must be 18 or greater"
+	if got := message(t, "en"); got != untranslated {
+		t.Errorf("before registering a locale: message = %q, want %q", got, untranslated)
+	}
+
+	if err := RegisterLocale(en.New(), enTranslations.RegisterDefaultTranslations); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct{ acceptLanguage, want string }{
+		{"en-US", english},
+		{"fr", untranslated},
+		{"", untranslated},
+	} {
+		if got := message(t, tt.acceptLanguage); got != tt.want {
+			t.Errorf("Accept-Language %q: message = %q, want %q", tt.acceptLanguage, got, tt.want)
+		}
+	}
+
+	if err := SetDefaultLocale("fr"); err == nil {
+		t.Error("SetDefaultLocale(fr) succeeded for an unregistered locale")
+	}
+	if err := SetDefaultLocale("en"); err != nil {
+		t.Fatal(err)
+	}
+	if got := message(t, "fr"); got != english {
+		t.Errorf("with English as the default locale: message = %q, want %q", got, english)
+	}
+}
@@ -0,0 +1,205 @@
+package gofastapi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// IdempotencyKeyHeader is the request header carrying the client's idempotency key
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotencyStore stores the responses of requests made with an idempotency key, e.g.
+// in memory or Redis
+type IdempotencyStore interface {
+	CacheStore
+	// Reserve marks key as in progress for at most ttl. It reports false if key is
+	// already reserved or has a stored response.
+	Reserve(key string, ttl time.Duration) bool
+	// Release removes the reservation of key
+	Release(key string)
+}
+
+// IdempotencyOption configures IdempotencyMiddleware
+type IdempotencyOption func(*idempotencyConfig)
+
+type idempotencyConfig struct {
+	methods []string
+	scope   func(r *http.Request) string
+}
+
+// WithIdempotentMethods sets the methods IdempotencyMiddleware applies to (default:
+// POST and PATCH)
+func WithIdempotentMethods(methods ...string) IdempotencyOption {
+	return func(c *idempotencyConfig) {
+		c.methods = methods
+	}
+}
+
+// WithIdempotencyScope scopes idempotency keys by caller, so one client's key can't
+// replay another client's response. scope returns the caller's identity, e.g. the
+// user ID set by an auth middleware or the Authorization header:
+//
+//	gofastapi.WithIdempotencyScope(func(r *http.Request) string {
+//	    return r.Header.Get("Authorization")
+//	})
+func WithIdempotencyScope(scope func(r *http.Request) string) IdempotencyOption {
+	return func(c *idempotencyConfig) {
+		c.scope = scope
+	}
+}
+
+// IdempotencyMiddleware makes requests safe to retry. The first request with a given
+// Idempotency-Key header runs as usual and its response is stored for ttl, keyed by
+// method, path and key; retries within ttl get the stored response, marked with
+// Idempotent-Replayed: true, without running the handler again. A retry arriving
+// while the first request is still running gets 409 Conflict, and one reusing the key
+// with a different body gets 422 Unprocessable Entity.
+//
+// Responses with a 5xx, 408, 409 or 429 status and streamed responses are not stored,
+// so their requests can be retried, and cookies are never replayed. Keys are shared by
+// all callers unless scoped with WithIdempotencyScope. Requests without the header,
+// or with other methods than POST and PATCH, pass through. A nil store keeps
+// responses in memory.
+func IdempotencyMiddleware(store IdempotencyStore, ttl time.Duration, opts ...IdempotencyOption) mux.MiddlewareFunc {
+	cfg := &idempotencyConfig{methods: []string{http.MethodPost, http.MethodPatch}}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if store == nil {
+		store = NewMemoryIdempotencyStore()
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			idempotencyKey := r.Header.Get(IdempotencyKeyHeader)
+			if idempotencyKey == "" || !slices.Contains(cfg.methods, r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := r.Method + " " + r.URL.Path + "\n" + idempotencyKey
+			if cfg.scope != nil {
+				key += "\n" + cfg.scope(r)
+			}
+			// Hash the body so a key can't be reused for a different request
+			var body []byte
+			if r.Body != nil {
+				var err error
+				if body, err = io.ReadAll(r.Body); err != nil {
+					writeErrorResponse(w, r, NewError(http.StatusBadRequest, "Failed to read request body"),
+						nil, slog.Default(), http.StatusBadRequest)
+					return
+				}
+				r.Body = io.NopCloser(bytes.NewReader(body))
+			}
+			hash := sha256.Sum256(body)
+
+			if replayIdempotent(w, r, store, key, hash[:]) {
+				return
+			}
+			if !store.Reserve(key, ttl) {
+				// The first request may have completed since the lookup
+				if replayIdempotent(w, r, store, key, hash[:]) {
+					return
+				}
+				writeErrorResponse(w, r, NewErrorWithCode(http.StatusConflict, "IDEMPOTENCY_CONFLICT",
+					"A request with this Idempotency-Key is still in progress"), nil, slog.Default(), http.StatusBadRequest)
+				return
+			}
+			defer store.Release(key)
+
+			recorder := &cacheRecorder{ResponseWriter: w}
+			next.ServeHTTP(recorder, r)
+			status := recorder.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			if storableIdempotent(status) && !recorder.streaming &&
+				!strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream") {
+				store.Set(key, &CachedResponse{
+					Status:      status,
+					Header:      sharedHeader(w.Header()),
+					Body:        recorder.body.Bytes(),
+					RequestHash: hash[:],
+				}, ttl)
+			}
+		})
+	}
+}
+
+// storableIdempotent reports whether a response with status may be replayed. Server
+// errors, timeouts, conflicts and rate limiting are transient, so retries run again.
+func storableIdempotent(status int) bool {
+	switch status {
+	case http.StatusRequestTimeout, http.StatusConflict, http.StatusTooManyRequests:
+		return false
+	}
+	return status < http.StatusInternalServerError
+}
+
+// replayIdempotent writes the response stored under key, if any, or 422 if it was
+// stored for a request with a body other than the one hashing to hash
+func replayIdempotent(w http.ResponseWriter, r *http.Request, store IdempotencyStore, key string, hash []byte) bool {
+	stored, ok := store.Get(key)
+	if !ok {
+		return false
+	}
+	if !bytes.Equal(stored.RequestHash, hash) {
+		writeErrorResponse(w, r, NewErrorWithCode(http.StatusUnprocessableEntity, "IDEMPOTENCY_KEY_REUSED",
+			"This Idempotency-Key was used with a different request body"), nil, slog.Default(), http.StatusBadRequest)
+		return true
+	}
+	for name, values := range stored.Header {
+		w.Header()[name] = append([]string(nil), values...)
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(stored.Status)
+	w.Write(stored.Body)
+	return true
+}
+
+// MemoryIdempotencyStore is an in-memory IdempotencyStore
+type MemoryIdempotencyStore struct {
+	*MemoryCacheStore
+	reserved map[string]time.Time // Key -> reservation expiry
+	mu       sync.Mutex
+}
+
+// NewMemoryIdempotencyStore creates an in-memory idempotency store
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		MemoryCacheStore: NewMemoryCacheStore(),
+		reserved:         make(map[string]time.Time),
+	}
+}
+
+// Reserve marks key as in progress unless it is reserved or has a stored response
+func (s *MemoryIdempotencyStore) Reserve(key string, ttl time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, stored := s.Get(key); stored {
+		return false
+	}
+	now := time.Now()
+	if expires, ok := s.reserved[key]; ok && now.Before(expires) {
+		return false
+	}
+	s.reserved[key] = now.Add(ttl)
+	return true
+}
+
+// Release removes the reservation of key
+func (s *MemoryIdempotencyStore) Release(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.reserved, key)
+}
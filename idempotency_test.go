@@ -0,0 +1,100 @@
+package gofastapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	var calls int
+	r := New()
+	r.Use(IdempotencyMiddleware(nil, time.Minute, WithIdempotencyScope(func(req *http.Request) string {
+		return req.Header.Get("Authorization")
+	})))
+	err := r.POST("/orders", func(ctx context.Context, req struct {
+		Fail bool `query:"fail"`
+	}) (int, error) {
+		calls++
+		if req.Fail {
+			return 0, NewError(http.StatusTooManyRequests, "slow down")
+		}
+		return calls, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	post := func(path, key, auth string) *TestResponse {
+		t.Helper()
+		resp, err := r.TestRequest(http.MethodPost, path, nil,
+			WithTestHeader(IdempotencyKeyHeader, key), WithTestHeader("Authorization", auth))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	post("/orders", "k1", "alice")
+	if resp := post("/orders", "k1", "alice"); resp.Header.Get("Idempotent-Replayed") != "true" {
+		t.Error("retry by the same caller was not replayed")
+	}
+	if resp := post("/orders", "k1", "bob"); resp.Header.Get("Idempotent-Replayed") != "" {
+		t.Error("another caller's request with the same key was replayed")
+	}
+	if calls != 2 {
+		t.Errorf("handler ran %d times, want 2", calls)
+	}
+
+	post("/orders?fail=true", "k2", "alice")
+	if resp := post("/orders?fail=true", "k2", "alice"); resp.Header.Get("Idempotent-Replayed") != "" {
+		t.Error("429 response was replayed")
+	}
+}
+
+func TestIdempotencyKeyReusedWithDifferentBody(t *testing.T) {
+	var calls int
+	r := New()
+	r.Use(IdempotencyMiddleware(nil, time.Minute))
+	err := r.POST("/payments", func(ctx context.Context, req struct {
+		Amount int `json:"amount"`
+	}) (int, error) {
+		calls++
+		return req.Amount, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	post := func(body string) *TestResponse {
+		t.Helper()
+		resp, err := r.TestRequest(http.MethodPost, "/payments", []byte(body),
+			WithTestHeader(IdempotencyKeyHeader, "k1"), WithTestHeader("Content-Type", "application/json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	if resp := post(`{"amount":10}`); resp.StatusCode != http.StatusOK {
+		t.Fatalf("first request: status = %d, want 200; body: %s", resp.StatusCode, resp.Body)
+	}
+	if resp := post(`{"amount":10}`); resp.Header.Get("Idempotent-Replayed") != "true" {
+		t.Error("retry with the same body was not replayed")
+	}
+	resp := post(`{"amount":1000}`)
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("retry with another body: status = %d, want 422", resp.StatusCode)
+	}
+	var body ErrorResponse
+	if err := resp.DecodeJSON(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Code != "IDEMPOTENCY_KEY_REUSED" {
+		t.Errorf("code = %q, want IDEMPOTENCY_KEY_REUSED", body.Code)
+	}
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want 1", calls)
+	}
+}
@@ -0,0 +1,76 @@
+package gofastapi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// JSONSchemaDialect is the $schema of documents generated by JSONSchemaFor
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchemaFor generates a standalone JSON Schema (Draft 2020-12) document for t,
+// e.g. a request or response type, for validating payloads outside Go. Referenced
+// types are included under $defs, and OpenAPI's example keyword becomes examples.
+func (b *OpenAPIBuilder) JSONSchemaFor(t reflect.Type) (*Schema, error) {
+	if t == nil {
+		return nil, fmt.Errorf("JSON schema requires a type")
+	}
+	base := t
+	for base.Kind() == reflect.Ptr {
+		base = base.Elem()
+	}
+	switch base.Kind() {
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+		return nil, fmt.Errorf("type %s cannot be represented in JSON", t)
+	}
+
+	// Generate into a separate builder so the spec's components are left untouched
+	b.mu.RLock()
+	generator := NewOpenAPIBuilder("", "")
+	generator.namingPolicy = b.namingPolicy
+	b.mu.RUnlock()
+
+	document := generator.createSchemaFromType(t, "")
+	document.SchemaURI = JSONSchemaDialect
+	if len(generator.spec.Components.Schemas) > 0 {
+		document.Defs = generator.spec.Components.Schemas
+	}
+	toJSONSchema(document)
+	for _, def := range document.Defs {
+		toJSONSchema(def)
+	}
+	return document, nil
+}
+
+// toJSONSchema converts a schema generated for OpenAPI to JSON Schema in place,
+// pointing references at $defs
+func toJSONSchema(schema *Schema) {
+	if schema == nil {
+		return
+	}
+	if name, ok := strings.CutPrefix(schema.Ref, "#/components/schemas/"); ok {
+		schema.Ref = "#/$defs/" + name
+	}
+	if schema.Example != nil {
+		schema.Examples = []interface{}{schema.Example}
+		schema.Example = nil
+	}
+	if schema.Discriminator != nil {
+		for value, ref := range schema.Discriminator.Mapping {
+			schema.Discriminator.Mapping[value] = strings.Replace(ref, "#/components/schemas/", "#/$defs/", 1)
+		}
+	}
+
+	for _, property := range schema.Properties {
+		toJSONSchema(property)
+	}
+	toJSONSchema(schema.Items)
+	toJSONSchema(schema.AdditionalProperties)
+	for _, sub := range schema.OneOf {
+		toJSONSchema(sub)
+	}
+	for _, sub := range schema.AllOf {
+		toJSONSchema(sub)
+	}
+}
@@ -0,0 +1,261 @@
+// Package jwtauth verifies JWT bearer tokens for gofastapi routes. Tokens are checked
+// for a valid signature, expiry, not-before time, audience and issuer, and their claims
+// are decoded into a struct that handlers receive through dependency injection:
+//
+//	type Claims struct {
+//	    jwtauth.RegisteredClaims
+//	    Role string `json:"role"`
+//	}
+//
+//	r.RegisterDependency("auth", jwtauth.NewBearer[Claims](jwtauth.Config{
+//	    Keys:     jwtauth.JWKS("https://issuer.example.com/.well-known/jwks.json"),
+//	    Audience: "my-api",
+//	}))
+//
+//	type MeRequest struct {
+//	    Claims Claims `dep:"auth"`
+//	}
+//
+// The package only depends on the standard library, so the core module stays
+// dependency-light for applications that don't use it.
+package jwtauth
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/priyanshu-shubham/gofastapi"
+)
+
+// Config configures token verification
+type Config struct {
+	// Keys resolves the verification key for a token, e.g. StaticKey or JWKS (required)
+	Keys KeyFunc
+	// Algorithms restricts the accepted signing algorithms (default: any supported
+	// algorithm matching the key type; "none" is never accepted)
+	Algorithms []string
+	// Audience, if set, must be one of the token's aud values
+	Audience string
+	// Issuer, if set, must equal the token's iss claim
+	Issuer string
+	// Leeway allows for clock skew when checking exp and nbf
+	Leeway time.Duration
+	// Now returns the current time (default: time.Now)
+	Now func() time.Time
+}
+
+// RegisteredClaims holds the standard JWT claims. Embed it in custom claim structs.
+type RegisteredClaims struct {
+	Issuer    string   `json:"iss,omitempty"`
+	Subject   string   `json:"sub,omitempty"`
+	Audience  Audience `json:"aud,omitempty"`
+	ExpiresAt *Time    `json:"exp,omitempty"`
+	NotBefore *Time    `json:"nbf,omitempty"`
+	IssuedAt  *Time    `json:"iat,omitempty"`
+	ID        string   `json:"jti,omitempty"`
+}
+
+// Audience is the aud claim, which may be encoded as a string or an array of strings
+type Audience []string
+
+// UnmarshalJSON accepts a single string or an array of strings
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return fmt.Errorf("aud must be a string or an array of strings")
+	}
+	*a = many
+	return nil
+}
+
+// Time is a NumericDate claim: seconds since the Unix epoch
+type Time struct {
+	time.Time
+}
+
+// UnmarshalJSON decodes a numeric date, which may have a fractional part
+func (t *Time) UnmarshalJSON(data []byte) error {
+	var seconds float64
+	if err := json.Unmarshal(data, &seconds); err != nil {
+		return fmt.Errorf("numeric date expected")
+	}
+	t.Time = time.Unix(0, int64(seconds*float64(time.Second)))
+	return nil
+}
+
+// MarshalJSON encodes the time as seconds since the Unix epoch
+func (t Time) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.Unix())
+}
+
+// BearerRequest binds the Authorization header for Bearer
+type BearerRequest struct {
+	Authorization string `header:"Authorization"`
+}
+
+// Bearer is a dependency that verifies the request's bearer token and injects its
+// claims decoded as C. Register it with Router.RegisterDependency, which documents
+// the bearer security scheme automatically.
+type Bearer[C any] struct {
+	config Config
+}
+
+// NewBearer creates a bearer token dependency yielding claims of type C
+func NewBearer[C any](config Config) *Bearer[C] {
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+	return &Bearer[C]{config: config}
+}
+
+// SecuritySchemes declares the bearer scheme for the OpenAPI spec
+func (b *Bearer[C]) SecuritySchemes() []gofastapi.SecuritySchemeType {
+	return []gofastapi.SecuritySchemeType{gofastapi.SecuritySchemeBearer}
+}
+
+// Handle verifies the token and decodes its claims, failing with 401 otherwise
+func (b *Bearer[C]) Handle(ctx context.Context, req BearerRequest) (C, error) {
+	var claims C
+	scheme, token, ok := strings.Cut(strings.TrimSpace(req.Authorization), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return claims, unauthorized("", "Missing bearer token")
+	}
+
+	payload, err := b.Verify(ctx, strings.TrimSpace(token))
+	if err != nil {
+		return claims, err
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return claims, unauthorized("invalid_token", "Invalid token claims")
+	}
+	return claims, nil
+}
+
+// Verify checks a compact JWT's signature and registered claims and returns its payload
+func (b *Bearer[C]) Verify(ctx context.Context, token string) ([]byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, unauthorized("invalid_token", "Malformed token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || json.Unmarshal(headerJSON, &header) != nil {
+		return nil, unauthorized("invalid_token", "Malformed token header")
+	}
+	if !b.algorithmAllowed(header.Alg) {
+		return nil, unauthorized("invalid_token", "Unsupported signing algorithm")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, unauthorized("invalid_token", "Malformed token signature")
+	}
+	if b.config.Keys == nil {
+		return nil, fmt.Errorf("jwtauth: no verification keys configured")
+	}
+	key, err := b.config.Keys(ctx, header.Kid, header.Alg)
+	if err != nil {
+		return nil, unauthorized("invalid_token", "Unknown signing key")
+	}
+	if err := verifySignature(header.Alg, key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, unauthorized("invalid_token", "Invalid token signature")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, unauthorized("invalid_token", "Malformed token payload")
+	}
+	var registered RegisteredClaims
+	if err := json.Unmarshal(payload, &registered); err != nil {
+		return nil, unauthorized("invalid_token", "Invalid token claims")
+	}
+	if err := b.checkClaims(registered); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// algorithmAllowed reports whether tokens signed with alg are accepted
+func (b *Bearer[C]) algorithmAllowed(alg string) bool {
+	if _, ok := algorithms[alg]; !ok {
+		return false
+	}
+	if len(b.config.Algorithms) == 0 {
+		return true
+	}
+	for _, allowed := range b.config.Algorithms {
+		if allowed == alg {
+			return true
+		}
+	}
+	return false
+}
+
+// checkClaims validates the time, audience and issuer claims
+func (b *Bearer[C]) checkClaims(claims RegisteredClaims) error {
+	now := b.config.Now()
+	if claims.ExpiresAt != nil && !now.Before(claims.ExpiresAt.Add(b.config.Leeway)) {
+		return unauthorized("invalid_token", "Token has expired")
+	}
+	if claims.NotBefore != nil && now.Add(b.config.Leeway).Before(claims.NotBefore.Time) {
+		return unauthorized("invalid_token", "Token is not valid yet")
+	}
+	if b.config.Issuer != "" && claims.Issuer != b.config.Issuer {
+		return unauthorized("invalid_token", "Invalid token issuer")
+	}
+	if b.config.Audience != "" {
+		for _, aud := range claims.Audience {
+			if aud == b.config.Audience {
+				return nil
+			}
+		}
+		return unauthorized("invalid_token", "Invalid token audience")
+	}
+	return nil
+}
+
+// AuthError is a 401 API error that also sets the WWW-Authenticate challenge
+type AuthError struct {
+	apiErr *gofastapi.Error
+	code   string // RFC 6750 error code, e.g. invalid_token
+}
+
+func (e *AuthError) Error() string {
+	return e.apiErr.Message
+}
+
+// Unwrap exposes the API error so the response keeps its status
+func (e *AuthError) Unwrap() error {
+	return e.apiErr
+}
+
+// ApplyHeaders sets the Bearer challenge
+func (e *AuthError) ApplyHeaders(h http.Header) {
+	challenge := "Bearer"
+	if e.code != "" {
+		challenge += fmt.Sprintf(" error=%q, error_description=%q", e.code, e.apiErr.Message)
+	}
+	h.Set("WWW-Authenticate", challenge)
+}
+
+// unauthorized creates a 401 error with an RFC 6750 error code
+func unauthorized(code, message string) *AuthError {
+	return &AuthError{
+		apiErr: gofastapi.NewErrorWithCode(http.StatusUnauthorized, "UNAUTHORIZED", message),
+		code:   code,
+	}
+}
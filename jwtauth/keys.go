@@ -0,0 +1,334 @@
+package jwtauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256" // Register SHA-256 for crypto.Hash
+	_ "crypto/sha512" // Register SHA-384 and SHA-512 for crypto.Hash
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// KeyFunc returns the key verifying a token with the given key ID and algorithm.
+// Keys are []byte for HMAC, *rsa.PublicKey, *ecdsa.PublicKey or ed25519.PublicKey.
+type KeyFunc func(ctx context.Context, kid, alg string) (interface{}, error)
+
+// StaticKey verifies every token with the same key
+func StaticKey(key interface{}) KeyFunc {
+	return func(ctx context.Context, kid, alg string) (interface{}, error) {
+		return key, nil
+	}
+}
+
+// JWKSOption configures JWKS
+type JWKSOption func(*jwks)
+
+// WithJWKSClient sets the HTTP client used to fetch the key set
+func WithJWKSClient(client *http.Client) JWKSOption {
+	return func(j *jwks) {
+		j.client = client
+	}
+}
+
+// WithJWKSRefresh sets how long a fetched key set is used before refetching (default: 1h)
+func WithJWKSRefresh(d time.Duration) JWKSOption {
+	return func(j *jwks) {
+		j.refresh = d
+	}
+}
+
+// JWKS verifies tokens with the key from a JSON Web Key Set matching the token's kid.
+// The set is fetched on first use and refetched after the refresh interval, or early
+// when a token names an unknown kid, so key rotation is picked up. Fetches are shared
+// by concurrent requests and attempted at most once a minute (or once per refresh
+// interval, if shorter), so unknown kids and issuer outages can't flood the issuer.
+func JWKS(url string, opts ...JWKSOption) KeyFunc {
+	j := &jwks{
+		url:     url,
+		client:  http.DefaultClient,
+		refresh: time.Hour,
+		now:     time.Now,
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+	return j.key
+}
+
+const (
+	// jwksRetryInterval bounds how often the key set is refetched
+	jwksRetryInterval = time.Minute
+	// jwksFetchTimeout bounds a fetch, which outlives the request that started it
+	jwksFetchTimeout = 30 * time.Second
+	// maxJWKSSize bounds the key set document
+	maxJWKSSize = 1 << 20
+)
+
+type jwks struct {
+	url     string
+	client  *http.Client
+	refresh time.Duration
+	now     func() time.Time
+
+	keys      map[string]interface{} // kid -> key
+	fetched   time.Time              // Last successful fetch
+	attempted time.Time              // Last fetch started, successful or not
+	inflight  *jwksFetch             // Fetch in progress, shared by concurrent callers
+	mu        sync.Mutex
+}
+
+// jwksFetch is an in-flight key set fetch that concurrent callers wait on
+type jwksFetch struct {
+	done chan struct{}
+	err  error
+}
+
+// key returns the key for kid, fetching the key set when stale or kid is unknown
+func (j *jwks) key(ctx context.Context, kid, alg string) (interface{}, error) {
+	j.mu.Lock()
+	now := j.now()
+	key, ok := j.keys[kid]
+	if ok && now.Sub(j.fetched) <= j.refresh {
+		j.mu.Unlock()
+		return key, nil
+	}
+	call := j.inflight
+	if call == nil && now.Sub(j.attempted) >= min(jwksRetryInterval, j.refresh) {
+		// Record the attempt before fetching so failures are rate-limited too
+		call = &jwksFetch{done: make(chan struct{})}
+		j.inflight = call
+		j.attempted = now
+		// Fetch without the request's cancellation, since other requests share it
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), jwksFetchTimeout)
+		go func() {
+			defer cancel()
+			j.fetch(fetchCtx, call)
+		}()
+	}
+	j.mu.Unlock()
+
+	if call == nil {
+		if ok {
+			return key, nil // Stale, but refetched too recently to try again
+		}
+		return nil, fmt.Errorf("jwtauth: no key with kid %q", kid)
+	}
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	j.mu.Lock()
+	key, ok = j.keys[kid]
+	j.mu.Unlock()
+	if ok {
+		return key, nil // A failed fetch keeps the cached keys while the issuer is unreachable
+	}
+	if call.err != nil {
+		return nil, call.err
+	}
+	return nil, fmt.Errorf("jwtauth: no key with kid %q", kid)
+}
+
+// fetch downloads the key set, publishing the outcome to the callers waiting on call
+func (j *jwks) fetch(ctx context.Context, call *jwksFetch) {
+	keys, err := j.download(ctx)
+
+	j.mu.Lock()
+	if err == nil {
+		j.keys = keys
+		j.fetched = j.now()
+	}
+	j.inflight = nil
+	call.err = err
+	j.mu.Unlock()
+	close(call.done)
+}
+
+// download fetches and parses the key set
+func (j *jwks) download(ctx context.Context) (map[string]interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwtauth: fetching JWKS: %s", resp.Status)
+	}
+
+	var set struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxJWKSSize)).Decode(&set); err != nil {
+		return nil, fmt.Errorf("jwtauth: decoding JWKS: %w", err)
+	}
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, raw := range set.Keys {
+		kid, key, err := ParseJWK(raw)
+		if err != nil {
+			continue // Skip keys of unsupported types
+		}
+		keys[kid] = key
+	}
+	return keys, nil
+}
+
+// ParseJWK parses a public JSON Web Key, returning its key ID and key
+func ParseJWK(data []byte) (string, interface{}, error) {
+	var jwk struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Crv string `json:"crv"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+	}
+	if err := json.Unmarshal(data, &jwk); err != nil {
+		return "", nil, err
+	}
+
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return "", nil, err
+		}
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return "", nil, err
+		}
+		return jwk.Kid, &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return "", nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return "", nil, err
+		}
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return "", nil, err
+		}
+		return jwk.Kid, &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return "", nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return "", nil, fmt.Errorf("invalid Ed25519 key")
+		}
+		return jwk.Kid, ed25519.PublicKey(x), nil
+	default:
+		return "", nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// algorithms maps supported JWS algorithms to their hash
+var algorithms = map[string]crypto.Hash{
+	"HS256": crypto.SHA256, "HS384": crypto.SHA384, "HS512": crypto.SHA512,
+	"RS256": crypto.SHA256, "RS384": crypto.SHA384, "RS512": crypto.SHA512,
+	"PS256": crypto.SHA256, "PS384": crypto.SHA384, "PS512": crypto.SHA512,
+	"ES256": crypto.SHA256, "ES384": crypto.SHA384, "ES512": crypto.SHA512,
+	"EdDSA": 0,
+}
+
+// ecdsaCurves maps the ES algorithms to the curve their keys must use
+var ecdsaCurves = map[string]string{"ES256": "P-256", "ES384": "P-384", "ES512": "P-521"}
+
+var errSignature = errors.New("signature verification failed")
+
+// verifySignature checks signature over signed with key, requiring the key type to match alg
+func verifySignature(alg string, key interface{}, signed, signature []byte) error {
+	hash := algorithms[alg]
+	var digest []byte
+	if hash != 0 {
+		h := hash.New()
+		h.Write(signed)
+		digest = h.Sum(nil)
+	}
+
+	switch alg[:2] {
+	case "HS":
+		secret, ok := key.([]byte)
+		if !ok {
+			return errSignature
+		}
+		mac := hmac.New(hash.New, secret)
+		mac.Write(signed)
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return errSignature
+		}
+		return nil
+	case "RS":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errSignature
+		}
+		return rsa.VerifyPKCS1v15(pub, hash, digest, signature)
+	case "PS":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errSignature
+		}
+		return rsa.VerifyPSS(pub, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	case "ES":
+		// Each ES algorithm is bound to one curve (RFC 7518 section 3.4)
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || pub.Curve == nil || pub.Curve.Params().Name != ecdsaCurves[alg] {
+			return errSignature
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errSignature
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return errSignature
+		}
+		return nil
+	case "Ed":
+		pub, ok := key.(ed25519.PublicKey)
+		if !ok || !ed25519.Verify(pub, signed, signature) {
+			return errSignature
+		}
+		return nil
+	}
+	return errSignature
+}
@@ -0,0 +1,92 @@
+package gofastapi
+
+import (
+	"net/http"
+	"time"
+)
+
+// MetricsObserver receives request metrics for typed and SSE routes. Paths are route
+// templates such as "/users/{id}", so label cardinality stays bounded.
+type MetricsObserver interface {
+	// ObserveRequest is called once a request completes. For SSE routes it is
+	// called when the stream ends, with the full stream duration.
+	ObserveRequest(method, path string, status int, dur time.Duration)
+	// IncInFlight is called when a request starts
+	IncInFlight(method, path string)
+	// DecInFlight is called when a request completes
+	DecInFlight(method, path string)
+}
+
+// SetMetricsObserver sets the observer notified around every route execution,
+// including time spent in middleware. Pass nil to disable metrics.
+func (r *Router) SetMetricsObserver(obs MetricsObserver) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = obs
+}
+
+// withMetrics reports request count, latency and in-flight requests for a route
+func (r *Router) withMetrics(method, path string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.mu.RLock()
+		obs := r.metrics
+		r.mu.RUnlock()
+		if obs == nil {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		start := time.Now()
+		obs.IncInFlight(method, path)
+		defer obs.DecInFlight(method, path)
+
+		recorder := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, req)
+		obs.ObserveRequest(method, path, recorder.Status(), time.Since(start))
+	})
+}
+
+// statusRecorder captures the status code written to a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records the status code
+func (s *statusRecorder) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+// Write records an implicit 200 status
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return s.ResponseWriter.Write(b)
+}
+
+// Flush supports streaming responses such as SSE
+func (s *statusRecorder) Flush() {
+	if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+		if s.status == 0 {
+			s.status = http.StatusOK
+		}
+		flusher.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// Status returns the recorded status, defaulting to 200 when nothing was written
+func (s *statusRecorder) Status() int {
+	if s.status == 0 {
+		return http.StatusOK
+	}
+	return s.status
+}
@@ -1,11 +1,20 @@
 package gofastapi
 
 import (
+	"encoding"
+	"encoding/json"
 	"fmt"
+	"maps"
 	"net/http"
+	"path"
 	"reflect"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
+	"unicode"
 )
 
 // OpenAPI types following OpenAPI 3.0 specification
@@ -25,8 +34,26 @@ type OpenAPIInfo struct {
 }
 
 type OpenAPIServer struct {
-	URL         string `json:"url"`
-	Description string `json:"description,omitempty"`
+	URL         string                    `json:"url"`
+	Description string                    `json:"description,omitempty"`
+	Variables   map[string]ServerVariable `json:"variables,omitempty"`
+}
+
+// ServerVariable is a variable of a templated server URL, e.g. the {region} in
+// https://{region}.api.example.com
+type ServerVariable struct {
+	Default     string   `json:"default"`
+	Enum        []string `json:"enum,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+// defaultURL returns the server URL with its variables replaced by their defaults
+func (s OpenAPIServer) defaultURL() string {
+	url := s.URL
+	for name, variable := range s.Variables {
+		url = strings.ReplaceAll(url, "{"+name+"}", variable.Default)
+	}
+	return url
 }
 
 type PathItem struct {
@@ -39,26 +66,111 @@ type PathItem struct {
 	Head    *Operation `json:"head,omitempty"`
 }
 
+// operations returns the non-nil operations of the path item
+func (p *PathItem) operations() []*Operation {
+	var operations []*Operation
+	for _, operation := range []*Operation{p.Get, p.Post, p.Put, p.Patch, p.Delete, p.Options, p.Head} {
+		if operation != nil {
+			operations = append(operations, operation)
+		}
+	}
+	return operations
+}
+
+// setOperation sets the operation for method, or removes it if operation is nil
+func (p *PathItem) setOperation(method string, operation *Operation) {
+	switch strings.ToUpper(method) {
+	case http.MethodGet:
+		p.Get = operation
+	case http.MethodPost:
+		p.Post = operation
+	case http.MethodPut:
+		p.Put = operation
+	case http.MethodPatch:
+		p.Patch = operation
+	case http.MethodDelete:
+		p.Delete = operation
+	case http.MethodOptions:
+		p.Options = operation
+	case http.MethodHead:
+		p.Head = operation
+	}
+}
+
+// eachOperation calls fn for every non-nil operation of the path item, in a fixed method order
+func (p *PathItem) eachOperation(fn func(method string, operation *Operation)) {
+	methods := []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions, http.MethodHead}
+	for i, operation := range []*Operation{p.Get, p.Post, p.Put, p.Patch, p.Delete, p.Options, p.Head} {
+		if operation != nil {
+			fn(methods[i], operation)
+		}
+	}
+}
+
 type Operation struct {
-	OperationID string                 `json:"operationId"`
-	Summary     string                 `json:"summary,omitempty"`
-	Description string                 `json:"description,omitempty"`
-	Tags        []string               `json:"tags,omitempty"`
-	Parameters  []Parameter            `json:"parameters,omitempty"`
-	RequestBody *RequestBody           `json:"requestBody,omitempty"`
-	Responses   map[string]interface{} `json:"responses"` // Can be *Response or *Ref
-	Security    []map[string][]string  `json:"security,omitempty"`
-	Deprecated  bool                   `json:"deprecated,omitempty"`
+	OperationID string                    `json:"operationId"`
+	Summary     string                    `json:"summary,omitempty"`
+	Description string                    `json:"description,omitempty"`
+	Tags        []string                  `json:"tags,omitempty"`
+	Parameters  []Parameter               `json:"parameters,omitempty"`
+	RequestBody *RequestBody              `json:"requestBody,omitempty"`
+	Responses   map[string]*ResponseOrRef `json:"responses"`
+	Security    []map[string][]string     `json:"security,omitempty"`
+	Deprecated  bool                      `json:"deprecated,omitempty"`
+	Extensions  map[string]interface{}    `json:"-"` // Vendor extensions emitted inline, e.g. "x-internal"
+}
+
+// MarshalJSON implements json.Marshaler to emit extensions as top-level keys
+func (o Operation) MarshalJSON() ([]byte, error) {
+	type operationAlias Operation
+	data, err := json.Marshal(operationAlias(o))
+	if err != nil {
+		return nil, err
+	}
+	return withExtensions(data, o.Extensions)
+}
+
+// withExtensions merges vendor extension keys into a marshaled JSON object
+func withExtensions(data []byte, extensions map[string]interface{}) ([]byte, error) {
+	if len(extensions) == 0 {
+		return data, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for key, value := range extensions {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("extension %s: %w", key, err)
+		}
+		fields[key] = raw
+	}
+	return json.Marshal(fields)
 }
 
 type Parameter struct {
-	Name        string      `json:"name"`
-	In          string      `json:"in"` // query, header, path, cookie
-	Description string      `json:"description,omitempty"`
-	Required    bool        `json:"required"`
-	Deprecated  bool        `json:"deprecated,omitempty"`
-	Schema      *Schema     `json:"schema"`
-	Example     interface{} `json:"example,omitempty"`
+	Ref         string               `json:"$ref,omitempty"` // Reference to components.parameters; only Name and In are kept alongside it, and only $ref is marshaled
+	Name        string               `json:"name"`
+	In          string               `json:"in"` // query, header, path, cookie
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required"`
+	Deprecated  bool                 `json:"deprecated,omitempty"`
+	Schema      *Schema              `json:"schema,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"` // For JSON-encoded parameters, instead of Schema
+	Style       string               `json:"style,omitempty"`   // Serialization of array and object values
+	Explode     *bool                `json:"explode,omitempty"`
+	Example     interface{}          `json:"example,omitempty"`
+	Examples    map[string]*Example  `json:"examples,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler so referenced parameters only emit $ref
+func (p Parameter) MarshalJSON() ([]byte, error) {
+	if p.Ref != "" {
+		return json.Marshal(Ref{Ref: p.Ref})
+	}
+	type parameterAlias Parameter
+	return json.Marshal(parameterAlias(p))
 }
 
 type RequestBody struct {
@@ -72,9 +184,57 @@ type Response struct {
 	Content     map[string]MediaType `json:"content,omitempty"`
 }
 
+// ResponseOrRef is an operation response: either an inline Response or a reference
+// to a shared one in components.responses
+type ResponseOrRef struct {
+	Ref      string    // e.g. "#/components/responses/ValidationError"; takes precedence
+	Response *Response // Inline response, used when Ref is empty
+}
+
+// inlineResponse wraps an inline response
+func inlineResponse(resp *Response) *ResponseOrRef {
+	return &ResponseOrRef{Response: resp}
+}
+
+// MarshalJSON implements json.Marshaler, emitting either {"$ref": ...} or the response
+func (r ResponseOrRef) MarshalJSON() ([]byte, error) {
+	if r.Ref != "" {
+		return json.Marshal(Ref{Ref: r.Ref})
+	}
+	return json.Marshal(r.Response)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (r *ResponseOrRef) UnmarshalJSON(data []byte) error {
+	var ref struct {
+		Ref string `json:"$ref"`
+	}
+	if err := json.Unmarshal(data, &ref); err != nil {
+		return err
+	}
+	if ref.Ref != "" {
+		*r = ResponseOrRef{Ref: ref.Ref}
+		return nil
+	}
+	var resp Response
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return err
+	}
+	*r = ResponseOrRef{Response: &resp}
+	return nil
+}
+
 type MediaType struct {
-	Schema  *Schema     `json:"schema"`
-	Example interface{} `json:"example,omitempty"`
+	Schema   *Schema             `json:"schema"`
+	Example  interface{}         `json:"example,omitempty"`
+	Examples map[string]*Example `json:"examples,omitempty"`
+}
+
+// Example is a named example value for a media type or parameter
+type Example struct {
+	Summary     string      `json:"summary,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Value       interface{} `json:"value,omitempty"`
 }
 
 // Ref represents a JSON reference
@@ -99,8 +259,47 @@ type Schema struct {
 	MaxLength            *int               `json:"maxLength,omitempty"`
 	MinItems             *int               `json:"minItems,omitempty"`
 	MaxItems             *int               `json:"maxItems,omitempty"`
+	UniqueItems          bool               `json:"uniqueItems,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
 	Enum                 []interface{}      `json:"enum,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Discriminator        *Discriminator     `json:"discriminator,omitempty"`
 	Ref                  string             `json:"$ref,omitempty"`
+	SchemaURI            string             `json:"$schema,omitempty"`  // JSON Schema documents only
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`    // JSON Schema documents only
+	Examples             []interface{}      `json:"examples,omitempty"` // JSON Schema only; OpenAPI 3.0 uses Example
+
+	// DisallowAdditionalProperties emits "additionalProperties": false
+	DisallowAdditionalProperties bool `json:"-"`
+	// Extensions are vendor extensions emitted inline, e.g. "x-go-type"
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// Discriminator names the property that selects a oneOf variant
+type Discriminator struct {
+	PropertyName string            `json:"propertyName"`
+	Mapping      map[string]string `json:"mapping,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler to support "additionalProperties": false
+// and inline extensions
+func (s Schema) MarshalJSON() ([]byte, error) {
+	type schemaAlias Schema
+	var data []byte
+	var err error
+	if !s.DisallowAdditionalProperties {
+		data, err = json.Marshal(schemaAlias(s))
+	} else {
+		data, err = json.Marshal(struct {
+			schemaAlias
+			AdditionalProperties bool `json:"additionalProperties"`
+		}{schemaAlias(s), false})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return withExtensions(data, s.Extensions)
 }
 
 type OpenAPIComponents struct {
@@ -129,10 +328,43 @@ type SecurityScheme struct {
 
 // OpenAPIBuilder builds OpenAPI specifications
 type OpenAPIBuilder struct {
-	spec          *OpenAPISpec
-	schemaCache   map[reflect.Type]string // Type -> Schema name in components
-	typeProcessor *typeProcessor
-	mu            sync.RWMutex
+	spec              *OpenAPISpec
+	schemaCache       map[reflect.Type]string // Type -> Schema name in components
+	schemaTypes       map[string]reflect.Type // Schema name in components -> Type
+	typeProcessor     *typeProcessor
+	validationStatus  int
+	errorEnvelope     reflect.Type // Type errors are wrapped in; nil when not enveloped
+	problemDetails    bool         // Error responses are RFC 7807 problem+json
+	responseEnvelope  ResponseEnvelope
+	responseEnvType   reflect.Type      // Type success responses are wrapped in
+	successResponses  []successResponse // JSON success responses, for rewrapping when the envelope changes
+	namingPolicy      NamingPolicy
+	paramReuseMin     int                 // Hoist parameters used at least this many times; 0 disables
+	dependencySchemes map[string][]string // Dependency name -> security scheme names
+	operationFilters  []OperationFilter
+	tags              tagMapping // Struct tags binding fields to request sources
+	mu                sync.RWMutex
+}
+
+// NamingPolicy controls how untagged struct fields are named in generated schemas
+type NamingPolicy string
+
+const (
+	NamingPolicyDefault   NamingPolicy = ""           // Go field name, as encoding/json emits it
+	NamingPolicySnakeCase NamingPolicy = "snake_case" // UserID -> user_id
+	NamingPolicyCamelCase NamingPolicy = "camelCase"  // UserID -> userID
+)
+
+// Apply converts a Go field name according to the policy
+func (p NamingPolicy) Apply(name string) string {
+	switch p {
+	case NamingPolicySnakeCase:
+		return toSnakeCase(name)
+	case NamingPolicyCamelCase:
+		return toLowerCamelCase(name)
+	default:
+		return name
+	}
 }
 
 type typeProcessor struct {
@@ -155,11 +387,14 @@ func NewOpenAPIBuilder(title, version string) *OpenAPIBuilder {
 				SecuritySchemes: make(map[string]*SecurityScheme),
 			},
 		},
-		schemaCache: make(map[reflect.Type]string),
+		schemaCache:       make(map[reflect.Type]string),
+		schemaTypes:       make(map[string]reflect.Type),
+		dependencySchemes: make(map[string][]string),
 		typeProcessor: &typeProcessor{
 			processed: make(map[reflect.Type]bool),
 			schemas:   make(map[string]*Schema),
 		},
+		validationStatus: http.StatusBadRequest,
 	}
 }
 
@@ -170,6 +405,133 @@ func (b *OpenAPIBuilder) SetDescription(description string) {
 	b.spec.Info.Description = description
 }
 
+// SetParameterReuse hoists parameters that appear identically in at least
+// minOccurrences operations into components.parameters. Zero disables hoisting.
+func (b *OpenAPIBuilder) SetParameterReuse(minOccurrences int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.paramReuseMin = minOccurrences
+}
+
+// hoistParameters replaces repeated inline parameters of spec with references to components
+func (b *OpenAPIBuilder) hoistParameters(spec *OpenAPISpec) {
+	if b.paramReuseMin <= 0 {
+		return
+	}
+	if spec.Components.Parameters == nil {
+		spec.Components.Parameters = make(map[string]*Parameter)
+	}
+
+	// Index existing components by content
+	componentByKey := make(map[string]string)
+	for name, param := range spec.Components.Parameters {
+		if key, err := json.Marshal(param); err == nil {
+			componentByKey[string(key)] = name
+		}
+	}
+
+	// Group inline parameters by content
+	type paramRef struct {
+		operation *Operation
+		index     int
+	}
+	usages := make(map[string][]paramRef)
+	var keys []string
+	for _, pathItem := range spec.Paths {
+		for _, operation := range pathItem.operations() {
+			for i, param := range operation.Parameters {
+				if param.Ref != "" {
+					continue
+				}
+				key, err := json.Marshal(param)
+				if err != nil {
+					continue
+				}
+				if _, seen := usages[string(key)]; !seen {
+					keys = append(keys, string(key))
+				}
+				usages[string(key)] = append(usages[string(key)], paramRef{operation, i})
+			}
+		}
+	}
+
+	sort.Strings(keys)
+	for _, key := range keys {
+		refs := usages[key]
+		name, exists := componentByKey[key]
+		if !exists {
+			if len(refs) < b.paramReuseMin {
+				continue
+			}
+			param := refs[0].operation.Parameters[refs[0].index]
+			name = parameterComponentName(spec.Components.Parameters, param)
+			spec.Components.Parameters[name] = &param
+		}
+		for _, ref := range refs {
+			// Keep the name and location so consumers can identify referenced parameters
+			param := ref.operation.Parameters[ref.index]
+			ref.operation.Parameters[ref.index] = Parameter{Ref: "#/components/parameters/" + name, Name: param.Name, In: param.In}
+		}
+	}
+}
+
+// parameterComponentName picks a component name for a parameter unused in components
+func parameterComponentName(components map[string]*Parameter, param Parameter) string {
+	candidates := []string{
+		sanitizeSchemaName(param.Name),
+		sanitizeSchemaName(param.In + "_" + param.Name),
+	}
+	for _, name := range candidates {
+		if _, taken := components[name]; !taken {
+			return name
+		}
+	}
+	for i := 2; ; i++ {
+		name := fmt.Sprintf("%s_%d", candidates[1], i)
+		if _, taken := components[name]; !taken {
+			return name
+		}
+	}
+}
+
+// SetNamingPolicy sets the naming policy for untagged fields in schemas added afterwards
+func (b *OpenAPIBuilder) SetNamingPolicy(policy NamingPolicy) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.namingPolicy = policy
+}
+
+// setTagMapping sets the struct tags that bind fields to request sources in routes
+// added afterwards
+func (b *OpenAPIBuilder) setTagMapping(tags tagMapping) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tags = tags
+}
+
+// SetValidationErrorStatus sets the status code documented for validation errors,
+// updating operations that were already added
+func (b *OpenAPIBuilder) SetValidationErrorStatus(status int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	oldCode := strconv.Itoa(b.validationStatus)
+	newCode := strconv.Itoa(status)
+	b.validationStatus = status
+	if oldCode == newCode {
+		return
+	}
+
+	for _, pathItem := range b.spec.Paths {
+		for _, operation := range pathItem.operations() {
+			if resp, ok := operation.Responses[oldCode]; ok {
+				delete(operation.Responses, oldCode)
+				operation.Responses[newCode] = resp
+			}
+		}
+	}
+}
+
 // AddServer adds a server to the spec
 func (b *OpenAPIBuilder) AddServer(url, description string) {
 	b.mu.Lock()
@@ -180,8 +542,129 @@ func (b *OpenAPIBuilder) AddServer(url, description string) {
 	})
 }
 
+// AddServerWithVariables adds a server with a templated URL. Every {name} in url must
+// have a variable with a default, which must be one of its enum values if it has any.
+func (b *OpenAPIBuilder) AddServerWithVariables(url, description string, vars map[string]ServerVariable) error {
+	declared := make(map[string]bool)
+	for _, name := range routeTemplateParams(url) {
+		declared[name] = true
+		variable, ok := vars[name]
+		if !ok {
+			return fmt.Errorf("server URL %s uses {%s}, which has no variable", url, name)
+		}
+		if variable.Default == "" {
+			return fmt.Errorf("server variable %s must have a default", name)
+		}
+		if len(variable.Enum) > 0 && !slices.Contains(variable.Enum, variable.Default) {
+			return fmt.Errorf("server variable %s: default %q is not one of its enum values %v", name, variable.Default, variable.Enum)
+		}
+	}
+	for name := range vars {
+		if !declared[name] {
+			return fmt.Errorf("server variable %s does not appear in server URL %s", name, url)
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.spec.Servers = append(b.spec.Servers, OpenAPIServer{
+		URL:         url,
+		Description: description,
+		Variables:   vars,
+	})
+	return nil
+}
+
 // AddSecurityScheme adds a security scheme
 func (b *OpenAPIBuilder) AddSecurityScheme(schemeType SecuritySchemeType) error {
+	_, err := b.addSecurityScheme(schemeType)
+	return err
+}
+
+// SetDependencySchemes adds the security schemes a dependency authenticates with
+// and records them for the security requirements of routes using the dependency
+func (b *OpenAPIBuilder) SetDependencySchemes(dep string, schemeTypes ...SecuritySchemeType) error {
+	var schemes []string
+	for _, schemeType := range schemeTypes {
+		schemeName, err := b.addSecurityScheme(schemeType)
+		if err != nil {
+			return err
+		}
+		schemes = append(schemes, schemeName)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(schemes) == 0 {
+		delete(b.dependencySchemes, dep)
+	} else {
+		b.dependencySchemes[dep] = schemes
+	}
+	return nil
+}
+
+// securityRequirements builds the security requirements for a route's dependencies.
+// Each requirement object must be fully satisfied (AND) and any one object suffices (OR):
+// the schemes of all dependencies are combined into one object, and a dependency with
+// several schemes yields one alternative object per scheme. Each anyOf group contributes
+// the schemes of all its members as alternatives; a member without schemes makes
+// authentication optional, documented as an empty requirement object.
+func (b *OpenAPIBuilder) securityRequirements(dependencies []string, anyOf [][]string) []map[string][]string {
+	// Each term lists alternative schemes, one of which is required; "" requires none
+	grouped := make(map[string]bool)
+	for _, group := range anyOf {
+		for _, dep := range group {
+			grouped[dep] = true
+		}
+	}
+	var terms [][]string
+	for _, dep := range dependencies {
+		if schemes := b.dependencySchemes[dep]; len(schemes) > 0 && !grouped[dep] {
+			terms = append(terms, schemes)
+		}
+	}
+	for _, group := range anyOf {
+		var alternatives []string
+		for _, dep := range group {
+			schemes := b.dependencySchemes[dep]
+			if len(schemes) == 0 {
+				schemes = []string{""}
+			}
+			for _, scheme := range schemes {
+				if !slices.Contains(alternatives, scheme) {
+					alternatives = append(alternatives, scheme)
+				}
+			}
+		}
+		terms = append(terms, alternatives)
+	}
+
+	requirements := []map[string][]string{{}}
+	for _, alternatives := range terms {
+		var combined []map[string][]string
+		for _, requirement := range requirements {
+			for _, scheme := range alternatives {
+				next := make(map[string][]string, len(requirement)+1)
+				for name, scopes := range requirement {
+					next[name] = scopes
+				}
+				if scheme != "" {
+					next[scheme] = []string{}
+				}
+				combined = append(combined, next)
+			}
+		}
+		requirements = combined
+	}
+
+	if len(requirements) == 1 && len(requirements[0]) == 0 {
+		return nil
+	}
+	return requirements
+}
+
+// addSecurityScheme adds a security scheme and returns its component name
+func (b *OpenAPIBuilder) addSecurityScheme(schemeType SecuritySchemeType) (string, error) {
 	scheme := &SecurityScheme{}
 	var schemeName string
 	switch schemeType {
@@ -202,16 +685,21 @@ func (b *OpenAPIBuilder) AddSecurityScheme(schemeType SecuritySchemeType) error
 		scheme.Description = "API Key authentication via X-API-Key header"
 		schemeName = "ApiKeyAuth"
 	default:
-		return fmt.Errorf("unknown security scheme type: %s", schemeType)
+		return "", fmt.Errorf("unknown security scheme type: %s", schemeType)
 	}
 	b.mu.Lock()
 	defer b.mu.Unlock()
 	b.spec.Components.SecuritySchemes[schemeName] = scheme
-	return nil
+	return schemeName, nil
 }
 
 // AddRoute adds a route to the OpenAPI spec
 func (b *OpenAPIBuilder) AddRoute(method, path string, handler *CompiledHandler, dependencies []string) {
+	b.addRoute(method, path, handler, dependencies, nil)
+}
+
+// addRoute adds a route to the OpenAPI spec, applying route options
+func (b *OpenAPIBuilder) addRoute(method, path string, handler *CompiledHandler, dependencies []string, cfg *routeConfig) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
@@ -227,24 +715,163 @@ func (b *OpenAPIBuilder) AddRoute(method, path string, handler *CompiledHandler,
 
 	// Create operation
 	operation := b.createOperation(method, openAPIPath, handler, dependencies)
+	if cfg != nil && len(cfg.responseVariants) > 0 {
+		b.applyResponseVariants(operation, cfg.responseVariants)
+	}
+	b.applyRouteConfig(operation, cfg, dependencies)
+	b.addSuccessResponse(operation, cfg)
 
 	// Set operation on path item
-	switch strings.ToUpper(method) {
-	case http.MethodGet:
-		pathItem.Get = operation
-	case http.MethodPost:
-		pathItem.Post = operation
-	case http.MethodPut:
-		pathItem.Put = operation
-	case http.MethodPatch:
-		pathItem.Patch = operation
-	case http.MethodDelete:
-		pathItem.Delete = operation
-	case http.MethodOptions:
-		pathItem.Options = operation
-	case http.MethodHead:
-		pathItem.Head = operation
+	pathItem.setOperation(method, operation)
+}
+
+// removeRoute removes the operation of a route, e.g. when a hidden route replaces it
+func (b *OpenAPIBuilder) removeRoute(method, path string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	openAPIPath := convertToOpenAPIPath(path)
+	pathItem, exists := b.spec.Paths[openAPIPath]
+	if !exists {
+		return
+	}
+	pathItem.setOperation(method, nil)
+	if len(pathItem.operations()) == 0 {
+		delete(b.spec.Paths, openAPIPath)
+	}
+}
+
+// OperationFilter modifies a generated operation, e.g. to add a common header parameter
+// or a vendor extension. path is the OpenAPI path template, e.g. "/users/{id}".
+type OperationFilter func(method, path string, op *Operation)
+
+// AddOperationFilter adds a filter run on every operation when the spec is returned by
+// GetSpec, after all other changes and before parameters are reused. Operations are
+// filtered sorted by path and method, and filters run in the order added.
+func (b *OpenAPIBuilder) AddOperationFilter(filter OperationFilter) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.operationFilters = append(b.operationFilters, filter)
+}
+
+// createResponseSchema creates the schema for a handler's response type.
+// Interface types accept any value unless they are registered unions or variants
+// are declared for the route.
+func (b *OpenAPIBuilder) createResponseSchema(t reflect.Type) *Schema {
+	if _, ok := lookupUnion(t); !ok && t.Kind() == reflect.Interface {
+		return &Schema{}
+	}
+	return b.createSchemaFromType(t, "")
+}
+
+// applyResponseVariants documents the success response as oneOf the variant types
+func (b *OpenAPIBuilder) applyResponseVariants(operation *Operation, variants []reflect.Type) {
+	response := operation.Responses["200"]
+	if response == nil || response.Response == nil {
+		return
+	}
+	schema := &Schema{}
+	for _, variant := range variants {
+		schema.OneOf = append(schema.OneOf, b.createSchemaFromType(variant, ""))
+	}
+	response.Response.Content["application/json"] = MediaType{Schema: schema}
+}
+
+// applyRouteConfig applies documentation-related route options to an operation
+func (b *OpenAPIBuilder) applyRouteConfig(operation *Operation, cfg *routeConfig, dependencies []string) {
+	if cfg == nil {
+		return
+	}
+
+	// Dependencies of which any one suffices are alternative requirements
+	if len(cfg.anyOf) > 0 {
+		operation.Security = b.securityRequirements(dependencies, cfg.anyOf)
+	}
+
+	if cfg.summary != "" {
+		operation.Summary = cfg.summary
+	}
+	operation.Tags = append(operation.Tags, cfg.tags...)
+
+	// Custom media type for the JSON request body
+	if operation.RequestBody != nil && cfg.requestContentType != "" {
+		if mediaType, ok := operation.RequestBody.Content["application/json"]; ok {
+			delete(operation.RequestBody.Content, "application/json")
+			operation.RequestBody.Content[cfg.requestContentType] = mediaType
+		}
+	}
+
+	// Vendor extensions
+	for key, value := range cfg.extensions {
+		if operation.Extensions == nil {
+			operation.Extensions = make(map[string]interface{})
+		}
+		operation.Extensions[key] = value
+	}
+
+	// Strict bodies reject unknown fields
+	if operation.RequestBody != nil && cfg.strictBody {
+		for _, mediaType := range operation.RequestBody.Content {
+			mediaType.Schema.DisallowAdditionalProperties = true
+		}
+	}
+
+	// Named request body examples
+	if operation.RequestBody != nil && len(cfg.requestExamples) > 0 {
+		for contentType, mediaType := range operation.RequestBody.Content {
+			mediaType.Examples = cfg.requestExamples
+			mediaType.Example = nil // example and examples are mutually exclusive
+			operation.RequestBody.Content[contentType] = mediaType
+		}
 	}
+
+	// Success response description and example
+	if success := successResponseOf(operation); success != nil {
+		if cfg.responseDescription != "" {
+			success.Description = cfg.responseDescription
+		}
+		if cfg.responseExample != nil {
+			if mediaType, ok := success.Content["application/json"]; ok {
+				mediaType.Example = cfg.responseExample
+				success.Content["application/json"] = mediaType
+			}
+		}
+	}
+
+	// Custom media type for the JSON success response
+	if success := operation.Responses["200"]; success != nil && success.Response != nil && cfg.responseContentType != "" {
+		if mediaType, ok := success.Response.Content["application/json"]; ok {
+			delete(success.Response.Content, "application/json")
+			success.Response.Content[cfg.responseContentType] = mediaType
+		}
+	}
+
+	// Named parameter examples
+	for i := range operation.Parameters {
+		examples, ok := cfg.parameterExamples[operation.Parameters[i].Name]
+		if !ok {
+			continue
+		}
+		param := &operation.Parameters[i]
+		if mediaType, ok := param.Content["application/json"]; ok {
+			mediaType.Examples = examples
+			mediaType.Example = nil
+			param.Content["application/json"] = mediaType
+		} else {
+			param.Examples = examples
+			param.Example = nil
+		}
+	}
+}
+
+// successResponseOf returns the operation's inline response with the lowest 2xx status
+func successResponseOf(operation *Operation) *Response {
+	for _, code := range slices.Sorted(maps.Keys(operation.Responses)) {
+		if strings.HasPrefix(code, "2") && operation.Responses[code].Response != nil {
+			return operation.Responses[code].Response
+		}
+	}
+	return nil
 }
 
 // createOperation creates an OpenAPI operation from a compiled handler
@@ -255,18 +882,11 @@ func (b *OpenAPIBuilder) createOperation(method, path string, handler *CompiledH
 	operation := &Operation{
 		OperationID: operationID,
 		Parameters:  []Parameter{},
-		Responses:   make(map[string]interface{}),
+		Responses:   make(map[string]*ResponseOrRef),
 	}
 
-	// Add security requirements if there are dependencies
-	if len(dependencies) > 0 {
-		operation.Security = []map[string][]string{}
-		for _, dep := range dependencies {
-			operation.Security = append(operation.Security, map[string][]string{
-				dep: {},
-			})
-		}
-	}
+	// Add security requirements for dependencies with security schemes
+	operation.Security = b.securityRequirements(dependencies, nil)
 
 	// Extract parameters and request body from request type
 	var requestBodySchema *Schema
@@ -287,55 +907,48 @@ func (b *OpenAPIBuilder) createOperation(method, path string, handler *CompiledH
 
 		// Get validation rules
 		validateTag := field.Tag.Get("validate")
-		isRequired := strings.Contains(validateTag, "required")
+		isRequired := isRequiredRule(validateTag)
 
 		// Extract description and example from tags
-		description := field.Tag.Get("description")
+		description := fieldDescription(handler.reqType, field)
 		example := field.Tag.Get("example")
 		defaultValue := field.Tag.Get("default")
 
 		// Handle different parameter types
-		if pathTag := field.Tag.Get("path"); pathTag != "" {
+		if sourceTag := field.Tag.Get("source"); sourceTag != "" {
+			operation.Parameters = append(operation.Parameters, b.createSourceParameters(field, sourceTag)...)
+		} else if _, ok := field.Tag.Lookup("in"); ok {
+			operation.Parameters = append(operation.Parameters, b.createQueryStructParameters(field.Type)...)
+		} else if pathTag := b.tags.tagFor(field, "path"); pathTag != "" {
 			param := Parameter{
 				Name:        pathTag,
 				In:          "path",
 				Required:    true, // Path params are always required
 				Description: description,
-				Schema:      b.createSchemaFromType(field.Type, validateTag),
-			}
-			if example != "" {
-				param.Example = example
-			}
-			operation.Parameters = append(operation.Parameters, param)
-		} else if queryTag := field.Tag.Get("query"); queryTag != "" {
-			schema := b.createSchemaFromType(field.Type, validateTag)
-			if defaultValue != "" {
-				schema.Default = parseValue(defaultValue, field.Type)
-			}
-			param := Parameter{
-				Name:        queryTag,
-				In:          "query",
-				Required:    isRequired,
-				Description: description,
-				Schema:      schema,
+				Schema:      b.createFieldSchema(field),
 			}
 			if example != "" {
-				param.Example = example
+				param.Example = parseValue(example, field.Type)
 			}
 			operation.Parameters = append(operation.Parameters, param)
-		} else if headerTag := field.Tag.Get("header"); headerTag != "" {
+		} else if queryTag := b.tags.tagFor(field, "query"); queryTag != "" {
+			operation.Parameters = append(operation.Parameters, b.createQueryParameter(handler.reqType, field, queryTag))
+		} else if headerTag := b.tags.tagFor(field, "header"); headerTag != "" {
 			param := Parameter{
 				Name:        headerTag,
 				In:          "header",
 				Required:    isRequired,
 				Description: description,
-				Schema:      b.createSchemaFromType(field.Type, validateTag),
+				Schema:      b.createFieldSchema(field),
 			}
 			if example != "" {
-				param.Example = example
+				param.Example = parseValue(example, field.Type)
 			}
+			useListStyle(&param)
 			operation.Parameters = append(operation.Parameters, param)
-		} else if jsonTag := field.Tag.Get("json"); jsonTag != "" && jsonTag != "-" {
+		} else if _, ok := field.Tag.Lookup("stream"); ok && isRecordStreamType(field.Type) {
+			operation.RequestBody = b.createStreamRequestBody(field)
+		} else if jsonTag := b.tags.tagFor(field, "body"); jsonTag != "" && jsonTag != "-" {
 			// This is part of the request body
 			if requestBodySchema == nil {
 				requestBodySchema = &Schema{
@@ -346,18 +959,17 @@ func (b *OpenAPIBuilder) createOperation(method, path string, handler *CompiledH
 			}
 
 			fieldName := strings.Split(jsonTag, ",")[0]
-			fieldSchema := b.createSchemaFromType(field.Type, validateTag)
+			fieldSchema := b.createFieldSchema(field)
 			fieldSchema.Description = description
 			if example != "" {
-				fieldSchema.Example = example
+				fieldSchema.Example = parseValue(example, field.Type)
 			}
 			if defaultValue != "" {
 				fieldSchema.Default = parseValue(defaultValue, field.Type)
 			}
 
-			requestBodySchema.Properties[fieldName] = fieldSchema
-			if isRequired {
-				requestBodyRequired = append(requestBodyRequired, fieldName)
+			if top := addBodyProperty(requestBodySchema, fieldName, fieldSchema, isRequired); top != "" && !slices.Contains(requestBodyRequired, top) {
+				requestBodyRequired = append(requestBodyRequired, top)
 			}
 		}
 	}
@@ -369,21 +981,44 @@ func (b *OpenAPIBuilder) createOperation(method, path string, handler *CompiledH
 			Required: len(requestBodyRequired) > 0,
 			Content: map[string]MediaType{
 				"application/json": {
-					Schema: requestBodySchema,
+					Schema:  requestBodySchema,
+					Example: b.composeExample(requestBodySchema, 0),
 				},
 			},
 		}
 	}
 
 	// Add response schema
-	responseSchema := b.getOrCreateSchema(handler.respType)
-	operation.Responses["200"] = &Response{
-		Description: "Successful response",
-		Content: map[string]MediaType{
-			"application/json": {
-				Schema: responseSchema,
-			},
-		},
+	if handler.respType == noContentType {
+		operation.Responses["204"] = inlineResponse(&Response{Description: "No content"})
+	} else if isFileStreamType(handler.respType) {
+		binary := map[string]MediaType{
+			"application/octet-stream": {Schema: &Schema{Type: "string", Format: "binary"}},
+		}
+		operation.Responses["200"] = inlineResponse(&Response{Description: "File content", Content: binary})
+		operation.Responses["206"] = inlineResponse(&Response{Description: "Partial file content for a Range request", Content: binary})
+	} else if isStreamType(handler.respType) {
+		operation.Responses["200"] = inlineResponse(&Response{
+			Description: "Streamed response",
+			Content: map[string]MediaType{
+				"application/octet-stream": {Schema: &Schema{Type: "string", Format: "binary"}},
+			},
+		})
+	} else {
+		responseSchema := b.createResponseSchema(handler.respType)
+		operation.Responses["200"] = inlineResponse(&Response{
+			Description: "Successful response",
+			Content: map[string]MediaType{
+				"application/json": {
+					Schema: responseSchema,
+				},
+			},
+		})
+	}
+
+	// Document revalidation for responses carrying an ETag or Last-Modified
+	if m := strings.ToUpper(method); (m == http.MethodGet || m == http.MethodHead) && (supportsConditional(handler.respType) || isFileStreamType(handler.respType)) {
+		operation.Responses["304"] = inlineResponse(&Response{Description: "Not Modified"})
 	}
 
 	// Add common error responses
@@ -434,7 +1069,9 @@ func (b *OpenAPIBuilder) createSchemaFromType(t reflect.Type, validateTag string
 	case reflect.Bool:
 		schema.Type = "boolean"
 	case reflect.Slice, reflect.Array:
-		if t.String() == "uuid.UUID" {
+		if t == rawMessageType {
+			return schema // Arbitrary embedded JSON
+		} else if isUUIDType(t) {
 			schema.Type = "string"
 			schema.Format = "uuid"
 		} else if t.String() == "[]uint8" {
@@ -454,8 +1091,24 @@ func (b *OpenAPIBuilder) createSchemaFromType(t reflect.Type, validateTag string
 			return b.getOrCreateSchema(t)
 		}
 	case reflect.Map:
+		// JSON object keys are strings whatever the key type, e.g. "1" for map[int]T
 		schema.Type = "object"
 		schema.AdditionalProperties = b.createSchemaFromType(t.Elem(), "")
+		// Maps keyed by an enum list its values as the expected properties
+		keys, _ := enumValues(t.Key())
+		for _, key := range keys {
+			if name, ok := jsonMapKey(key); ok {
+				if schema.Properties == nil {
+					schema.Properties = make(map[string]*Schema)
+				}
+				schema.Properties[name] = schema.AdditionalProperties
+			}
+		}
+	case reflect.Interface:
+		if union, ok := lookupUnion(t); ok {
+			return b.getOrCreateUnionSchema(t, union)
+		}
+		return schema // Any value, e.g. map[string]interface{} values
 	default:
 		schema.Type = "string" // Default fallback
 	}
@@ -463,9 +1116,251 @@ func (b *OpenAPIBuilder) createSchemaFromType(t reflect.Type, validateTag string
 	// Apply validation constraints
 	b.applyValidationConstraints(schema, validateTag)
 
+	// Enum types list their allowed values
+	if enum := enumSchemaValues(t); enum != nil {
+		schema.Enum = enum
+	}
+
 	return schema
 }
 
+var rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+
+// jsonMapKey returns the object key encoding/json emits for the map key k
+func jsonMapKey(k reflect.Value) (string, bool) {
+	if marshaler, ok := k.Interface().(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		return string(text), err == nil
+	}
+	switch k.Kind() {
+	case reflect.String:
+		return k.String(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(k.Uint(), 10), true
+	}
+	return "", false
+}
+
+// addBodyProperty adds a body field schema at a dotted JSON path such as "address.zip",
+// creating nested object schemas as needed. Parents of a required field are required
+// too. It returns the top-level property name if it must be listed as required.
+func addBodyProperty(root *Schema, path string, fieldSchema *Schema, required bool) string {
+	parts := strings.Split(path, ".")
+	parent := root
+	for i, part := range parts[:len(parts)-1] {
+		child := parent.Properties[part]
+		if child == nil || child.Properties == nil {
+			child = &Schema{Type: "object", Properties: make(map[string]*Schema)}
+			parent.Properties[part] = child
+		}
+		if required && i > 0 && !slices.Contains(parent.Required, part) {
+			parent.Required = append(parent.Required, part)
+		}
+		parent = child
+	}
+
+	leaf := parts[len(parts)-1]
+	parent.Properties[leaf] = fieldSchema
+	if required && len(parts) > 1 && !slices.Contains(parent.Required, leaf) {
+		parent.Required = append(parent.Required, leaf)
+	}
+
+	if !required {
+		return ""
+	}
+	return parts[0]
+}
+
+// createStreamRequestBody documents a RecordStream field as an NDJSON request body
+// whose lines each match the record schema
+func (b *OpenAPIBuilder) createStreamRequestBody(field reflect.StructField) *RequestBody {
+	return &RequestBody{
+		Description: "Newline-delimited JSON records, one per line",
+		Required:    true,
+		Content: map[string]MediaType{
+			"application/x-ndjson": {
+				Schema: b.createSchemaFromType(streamRecordType(field.Type), ""),
+			},
+		},
+	}
+}
+
+// createSourceParameters creates one parameter per location of a multi-source field.
+// Each location is optional on its own since any of them can supply the value.
+func (b *OpenAPIBuilder) createSourceParameters(field reflect.StructField, sourceTag string) []Parameter {
+	sources, err := parseSourceTag(sourceTag)
+	if err != nil {
+		return nil
+	}
+
+	description := field.Tag.Get("description")
+	example := field.Tag.Get("example")
+
+	var params []Parameter
+	for i, source := range sources {
+		param := Parameter{
+			Name:        source.name,
+			In:          source.in,
+			Required:    source.in == "path", // Path params are always required
+			Description: description,
+			Schema:      b.createFieldSchema(field),
+		}
+		if len(sources) > 1 {
+			param.Description = strings.TrimSpace(fmt.Sprintf("%s (source %d of %d, first non-empty wins)", description, i+1, len(sources)))
+		}
+		if example != "" {
+			param.Example = parseValue(example, field.Type)
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+// isParameterField reports whether a field is bound from path, query or header values
+func isParameterField(field reflect.StructField) bool {
+	for _, tag := range []string{"path", "query", "header", "source"} {
+		if field.Tag.Get(tag) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// useListStyle documents an array header parameter as a comma-separated list,
+// which repeated header lines are equivalent to
+func useListStyle(param *Parameter) {
+	if param.Schema == nil || param.Schema.Type != "array" {
+		return
+	}
+	explode := false
+	param.Style = "simple"
+	param.Explode = &explode
+}
+
+// useDelimiterStyle documents an array query parameter split on pipes or spaces by its
+// delimiter tag as pipeDelimited or spaceDelimited. Comma-separated lists keep the
+// default form style, as repeated keys are accepted too.
+func useDelimiterStyle(param *Parameter, field reflect.StructField) {
+	if param.Schema == nil || param.Schema.Type != "array" {
+		return
+	}
+	var style string
+	switch field.Tag.Get("delimiter") {
+	case "|":
+		style = "pipeDelimited"
+	case " ":
+		style = "spaceDelimited"
+	default:
+		return
+	}
+	explode := false
+	param.Style = style
+	param.Explode = &explode
+}
+
+// useJSONContent documents a parameter as a JSON-encoded value by moving
+// its schema and example into application/json content
+func useJSONContent(param *Parameter) {
+	param.Content = map[string]MediaType{
+		"application/json": {
+			Schema:  param.Schema,
+			Example: param.Example,
+		},
+	}
+	param.Schema = nil
+	param.Example = nil
+}
+
+// createQueryParameter creates the parameter of a query-tagged field of structType
+func (b *OpenAPIBuilder) createQueryParameter(structType reflect.Type, field reflect.StructField, name string) Parameter {
+	schema := b.createFieldSchema(field)
+	if defaultValue := field.Tag.Get("default"); defaultValue != "" {
+		schema.Default = parseValue(defaultValue, field.Type)
+	}
+	param := Parameter{
+		Name:        name,
+		In:          "query",
+		Required:    isRequiredRule(field.Tag.Get("validate")),
+		Description: fieldDescription(structType, field),
+		Schema:      schema,
+	}
+	if example := field.Tag.Get("example"); example != "" {
+		param.Example = parseValue(example, field.Type)
+	}
+	if isJSONQueryType(field.Type) {
+		useJSONContent(&param)
+	}
+	useDelimiterStyle(&param, field)
+	return param
+}
+
+// createQueryStructParameters flattens the fields of an in:"query" struct into
+// individual query parameters
+func (b *OpenAPIBuilder) createQueryStructParameters(structType reflect.Type) []Parameter {
+	var params []Parameter
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		if _, ok := field.Tag.Lookup("in"); ok {
+			params = append(params, b.createQueryStructParameters(field.Type)...)
+		} else if queryTag := b.tags.tagFor(field, "query"); queryTag != "" {
+			params = append(params, b.createQueryParameter(structType, field, queryTag))
+		}
+	}
+	return params
+}
+
+// createFieldSchema creates a schema for a struct field, honoring field-level tags
+func (b *OpenAPIBuilder) createFieldSchema(field reflect.StructField) *Schema {
+	schema := b.createSchemaFromType(field.Type, field.Tag.Get("validate"))
+
+	// Parameters parsed by a custom converter arrive as strings
+	if isParameterField(field) && hasConverter(field.Type) && schema.Type != "string" {
+		schema = &Schema{Type: "string"}
+		if field.Type == reflect.TypeOf(time.Duration(0)) {
+			schema.Format = "duration"
+		}
+	}
+
+	if pattern := field.Tag.Get("pattern"); pattern != "" && schema.Ref == "" {
+		schema.Pattern = pattern
+	}
+	return schema
+}
+
+// getOrCreateUnionSchema gets or creates the component schema of a union: a oneOf of
+// its variants with a discriminator mapping each value to its variant's schema
+func (b *OpenAPIBuilder) getOrCreateUnionSchema(t reflect.Type, union *unionType) *Schema {
+	if schemaName, exists := b.schemaCache[t]; exists {
+		return &Schema{Ref: "#/components/schemas/" + schemaName}
+	}
+
+	schemaName := b.schemaNameFor(t)
+	schema := &Schema{
+		Title: t.Name(),
+		Discriminator: &Discriminator{
+			PropertyName: union.discriminator,
+			Mapping:      make(map[string]string),
+		},
+	}
+	b.spec.Components.Schemas[schemaName] = schema
+	b.schemaCache[t] = schemaName
+	b.schemaTypes[schemaName] = t
+
+	for _, value := range union.values {
+		variant := b.createSchemaFromType(union.variants[value], "")
+		if !slices.ContainsFunc(schema.OneOf, func(s *Schema) bool { return s.Ref == variant.Ref }) {
+			schema.OneOf = append(schema.OneOf, variant)
+		}
+		schema.Discriminator.Mapping[value] = variant.Ref
+	}
+	return &Schema{Ref: "#/components/schemas/" + schemaName}
+}
+
 // getOrCreateSchema gets or creates a schema in components
 func (b *OpenAPIBuilder) getOrCreateSchema(t reflect.Type) *Schema {
 	// Check cache
@@ -474,51 +1369,150 @@ func (b *OpenAPIBuilder) getOrCreateSchema(t reflect.Type) *Schema {
 	}
 
 	// Generate schema name
-	schemaName := t.Name()
-	if schemaName == "" {
-		schemaName = "Schema" + fmt.Sprintf("%d", len(b.spec.Components.Schemas))
-	}
+	schemaName := b.schemaNameFor(t)
 
 	// Create the schema
 	schema := &Schema{
 		Type:       "object",
+		Title:      t.Name(),
 		Properties: make(map[string]*Schema),
 		Required:   []string{},
 	}
 
-	// Process struct fields
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
+	// Store in components and cache before processing fields so that
+	// self-referential types resolve to a $ref instead of recursing forever
+	b.spec.Components.Schemas[schemaName] = schema
+	b.schemaCache[t] = schemaName
+	b.schemaTypes[schemaName] = t
+
+	// Process struct fields, composing named embedded structs with allOf
+	embedded := b.addDirectProperties(t, schema)
+	var bases []*Schema
+	for _, embeddedType := range embedded {
+		base := b.embeddedBase(embeddedType, schema, bases)
+		if base == nil {
+			bases = nil
+			break
+		}
+		bases = append(bases, base)
+	}
+	if len(bases) == 0 {
+		for _, embeddedType := range embedded {
+			b.promoteProperties(embeddedType, schema)
+		}
+	} else {
+		composed := &Schema{Title: schema.Title, AllOf: bases}
+		if len(schema.Properties) > 0 {
+			schema.Title = ""
+			composed.AllOf = append(composed.AllOf, schema)
+		}
+		b.spec.Components.Schemas[schemaName] = composed
+	}
 
-		// Skip unexported fields
-		if field.PkgPath != "" {
-			continue
+	// Return reference
+	return &Schema{Ref: "#/components/schemas/" + schemaName}
+}
+
+// embeddedBase returns a reference to the component schema of an embedded struct, for
+// use in allOf. It returns nil if the embedded structs must be flattened instead: when
+// one is unnamed, or when its fields are shadowed by the embedding struct or clash with
+// an earlier base, since allOf cannot express Go's field precedence.
+func (b *OpenAPIBuilder) embeddedBase(t reflect.Type, schema *Schema, bases []*Schema) *Schema {
+	if t.Name() == "" || t.String() == "time.Time" {
+		return nil
+	}
+	promoted := &Schema{Properties: make(map[string]*Schema)}
+	b.addStructProperties(t, promoted)
+	for name := range promoted.Properties {
+		if _, exists := schema.Properties[name]; exists {
+			return nil
+		}
+		for _, base := range bases {
+			if b.hasProperty(base, name) {
+				return nil
+			}
+		}
+	}
+	return b.getOrCreateSchema(t)
+}
+
+// hasProperty reports whether a schema, resolving references and allOf, defines a property
+func (b *OpenAPIBuilder) hasProperty(schema *Schema, name string) bool {
+	if schema == nil {
+		return false
+	}
+	if schema.Ref != "" {
+		return b.hasProperty(b.spec.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")], name)
+	}
+	if _, ok := schema.Properties[name]; ok {
+		return true
+	}
+	for _, part := range schema.AllOf {
+		if b.hasProperty(part, name) {
+			return true
 		}
+	}
+	return false
+}
+
+// addStructProperties adds the JSON properties of a struct type to schema.
+// Fields of embedded structs without a JSON name are promoted into the parent,
+// matching encoding/json; direct fields take precedence over promoted ones.
+func (b *OpenAPIBuilder) addStructProperties(t reflect.Type, schema *Schema) {
+	for _, embeddedType := range b.addDirectProperties(t, schema) {
+		b.promoteProperties(embeddedType, schema)
+	}
+}
+
+// addDirectProperties adds a struct type's own JSON properties to schema and returns
+// the embedded structs whose fields would be promoted
+func (b *OpenAPIBuilder) addDirectProperties(t reflect.Type, schema *Schema) []reflect.Type {
+	var embedded []reflect.Type
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
 
 		// Get JSON tag
 		jsonTag := field.Tag.Get("json")
 		if jsonTag == "-" {
 			continue
 		}
+		fieldName := strings.Split(jsonTag, ",")[0]
+
+		// Collect embedded structs to promote after direct fields
+		if field.Anonymous && fieldName == "" {
+			embeddedType := field.Type
+			if embeddedType.Kind() == reflect.Ptr {
+				embeddedType = embeddedType.Elem()
+			}
+			if embeddedType.Kind() == reflect.Struct {
+				embedded = append(embedded, embeddedType)
+				continue
+			}
+		}
+
+		// Skip unexported fields
+		if field.PkgPath != "" {
+			continue
+		}
 
-		fieldName := field.Name
-		if jsonTag != "" {
-			fieldName = strings.Split(jsonTag, ",")[0]
+		if fieldName == "" {
+			fieldName = b.namingPolicy.Apply(field.Name)
 		}
 
 		// Get validation rules
 		validateTag := field.Tag.Get("validate")
-		isRequired := strings.Contains(validateTag, "required")
+		isRequired := isRequiredRule(validateTag)
 
 		// Create field schema
-		fieldSchema := b.createSchemaFromType(field.Type, validateTag)
+		fieldSchema := b.createFieldSchema(field)
 
 		// Add description and example if present
-		if desc := field.Tag.Get("description"); desc != "" {
+		if desc := fieldDescription(t, field); desc != "" {
 			fieldSchema.Description = desc
 		}
 		if example := field.Tag.Get("example"); example != "" {
-			fieldSchema.Example = example
+			fieldSchema.Example = parseValue(example, field.Type)
 		}
 
 		schema.Properties[fieldName] = fieldSchema
@@ -527,12 +1521,79 @@ func (b *OpenAPIBuilder) getOrCreateSchema(t reflect.Type) *Schema {
 		}
 	}
 
-	// Store in components and cache
-	b.spec.Components.Schemas[schemaName] = schema
-	b.schemaCache[t] = schemaName
+	return embedded
+}
 
-	// Return reference
-	return &Schema{Ref: "#/components/schemas/" + schemaName}
+// promoteProperties flattens an embedded struct's properties into schema; properties
+// already present take precedence
+func (b *OpenAPIBuilder) promoteProperties(embeddedType reflect.Type, schema *Schema) {
+	promoted := &Schema{Properties: make(map[string]*Schema)}
+	b.addStructProperties(embeddedType, promoted)
+
+	for name, propSchema := range promoted.Properties {
+		if _, exists := schema.Properties[name]; exists {
+			continue
+		}
+		schema.Properties[name] = propSchema
+	}
+	for _, name := range promoted.Required {
+		if schema.Properties[name] == promoted.Properties[name] {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// validationPatterns maps validator tags to equivalent regular expressions
+var validationPatterns = map[string]string{
+	"alpha":       `^[a-zA-Z]+$`,
+	"alphanum":    `^[a-zA-Z0-9]+$`,
+	"numeric":     `^[-+]?[0-9]+(?:\.[0-9]+)?$`,
+	"number":      `^[0-9]+$`,
+	"hexadecimal": `^(0[xX])?[0-9a-fA-F]+$`,
+	"hexcolor":    `^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{4}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`,
+	"ascii":       `^[\x00-\x7F]*$`,
+	"lowercase":   `^[^A-Z]*$`,
+	"uppercase":   `^[^a-z]*$`,
+}
+
+// schemaNameFor picks a component name for a type, qualifying it with its
+// package when the plain type name is already taken by a different type
+func (b *OpenAPIBuilder) schemaNameFor(t reflect.Type) string {
+	if t.Name() == "" {
+		return "Schema" + fmt.Sprintf("%d", len(b.spec.Components.Schemas))
+	}
+
+	candidates := []string{
+		sanitizeSchemaName(t.Name()),
+		sanitizeSchemaName(path.Base(t.PkgPath()) + "." + t.Name()),
+		sanitizeSchemaName(t.PkgPath() + "." + t.Name()),
+	}
+	for _, name := range candidates {
+		if existing, taken := b.schemaTypes[name]; !taken || existing == t {
+			return name
+		}
+	}
+
+	// Fall back to a numeric suffix if even the full path collides
+	base := candidates[len(candidates)-1]
+	for i := 2; ; i++ {
+		name := fmt.Sprintf("%s_%d", base, i)
+		if _, taken := b.schemaTypes[name]; !taken {
+			return name
+		}
+	}
+}
+
+// sanitizeSchemaName replaces characters not allowed in component names
+func sanitizeSchemaName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
 }
 
 // applyValidationConstraints applies validation constraints to a schema
@@ -542,9 +1603,43 @@ func (b *OpenAPIBuilder) applyValidationConstraints(schema *Schema, validateTag
 	}
 
 	parts := strings.Split(validateTag, ",")
+	inKeys := false
 	for _, part := range parts {
 		part = strings.TrimSpace(part)
 
+		// Map key constraints have no schema equivalent
+		if part == "keys" || part == "endkeys" {
+			inKeys = part == "keys"
+			continue
+		}
+		if inKeys {
+			continue
+		}
+
+		// Constraints after dive apply to slice elements or map values
+		if part == "dive" {
+			switch {
+			case schema.Type == "array" && schema.Items != nil && schema.Items.Ref == "":
+				schema = schema.Items
+			case schema.Type == "object" && schema.AdditionalProperties != nil && schema.AdditionalProperties.Ref == "":
+				schema = schema.AdditionalProperties
+			default:
+				return // Referenced element schemas can't carry constraints
+			}
+			continue
+		}
+
+		if part == "unique" && schema.Type == "array" {
+			schema.UniqueItems = true
+			continue
+		}
+
+		// Map regex-backed validator tags to their pattern
+		if pattern, ok := validationPatterns[part]; ok && schema.Type == "string" {
+			schema.Pattern = pattern
+			continue
+		}
+
 		// Handle min/max constraints
 		if strings.HasPrefix(part, "min=") {
 			if val := parseIntConstraint(part[4:]); val != nil {
@@ -572,83 +1667,299 @@ func (b *OpenAPIBuilder) applyValidationConstraints(schema *Schema, validateTag
 	}
 }
 
-// addErrorResponses adds common error responses
-func (b *OpenAPIBuilder) addErrorResponses(operation *Operation) {
-	// Add 400 Bad Request
-	if _, exists := b.spec.Components.Responses["ValidationError"]; !exists {
-		b.spec.Components.Responses["ValidationError"] = &Response{
-			Description: "Validation error",
-			Content: map[string]MediaType{
-				"application/json": {
-					Schema: &Schema{
+// errorResponseComponent describes a shared error response in components
+type errorResponseComponent struct {
+	description string
+	schema      func() *Schema // Schema of the un-enveloped ErrorResponse
+}
+
+var errorResponseComponents = map[string]errorResponseComponent{
+	"ValidationError": {
+		description: "Validation error",
+		schema: func() *Schema {
+			return &Schema{
+				Type: "object",
+				Properties: map[string]*Schema{
+					"code":    {Type: "string"},
+					"message": {Type: "string"},
+					"validation_errors": {
 						Type: "object",
-						Properties: map[string]*Schema{
-							"code":    {Type: "string"},
-							"message": {Type: "string"},
-							"validation_errors": {
-								Type: "object",
-								AdditionalProperties: &Schema{
-									Type:  "array",
-									Items: &Schema{Type: "string"},
-								},
-							},
+						AdditionalProperties: &Schema{
+							Type:  "array",
+							Items: &Schema{Type: "string"},
 						},
 					},
 				},
-			},
+			}
+		},
+	},
+	"UnauthorizedError": {
+		description: "Authentication required",
+		schema:      basicErrorSchema,
+	},
+	"InternalError": {
+		description: "Internal server error",
+		schema:      basicErrorSchema,
+	},
+}
+
+func basicErrorSchema() *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"code":    {Type: "string"},
+			"message": {Type: "string"},
+		},
+	}
+}
+
+// setErrorEnvelope sets the type error responses are wrapped in so the documented
+// error schemas match the wire format; nil documents them unwrapped
+func (b *OpenAPIBuilder) setErrorEnvelope(envelopeType reflect.Type) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.errorEnvelope = envelopeType
+
+	// Rebuild error responses that were already added
+	for name := range errorResponseComponents {
+		if _, exists := b.spec.Components.Responses[name]; exists {
+			delete(b.spec.Components.Responses, name)
+			b.ensureErrorResponse(name)
 		}
 	}
-	// Use Ref type for reference
-	operation.Responses["400"] = &Ref{Ref: "#/components/responses/ValidationError"}
+}
 
-	// Add 401 Unauthorized if security is required
-	if len(operation.Security) > 0 {
-		if _, exists := b.spec.Components.Responses["UnauthorizedError"]; !exists {
-			b.spec.Components.Responses["UnauthorizedError"] = &Response{
-				Description: "Authentication required",
-				Content: map[string]MediaType{
-					"application/json": {
-						Schema: &Schema{
-							Type: "object",
-							Properties: map[string]*Schema{
-								"code":    {Type: "string"},
-								"message": {Type: "string"},
-							},
-						},
-					},
-				},
-			}
+// SetProblemDetails sets whether error responses are documented as RFC 7807
+// application/problem+json, as written by ProblemDetailsErrorHandler
+func (b *OpenAPIBuilder) SetProblemDetails(enabled bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.problemDetails == enabled {
+		return
+	}
+	b.problemDetails = enabled
+
+	// Rebuild error responses that were already added
+	for name := range errorResponseComponents {
+		if _, exists := b.spec.Components.Responses[name]; exists {
+			delete(b.spec.Components.Responses, name)
+			b.ensureErrorResponse(name)
 		}
-		// Use Ref type for reference
-		operation.Responses["401"] = &Ref{Ref: "#/components/responses/UnauthorizedError"}
 	}
+}
 
-	// Add 500 Internal Server Error
-	if _, exists := b.spec.Components.Responses["InternalError"]; !exists {
-		b.spec.Components.Responses["InternalError"] = &Response{
-			Description: "Internal server error",
+// successResponse is an operation's JSON success response with its unwrapped schema
+// and example
+type successResponse struct {
+	response    *Response
+	contentType string
+	schema      *Schema
+	example     interface{}
+}
+
+// setResponseEnvelope sets the envelope applied to JSON success responses and the
+// type it returns, so the documented success schemas and examples match the wire format
+func (b *OpenAPIBuilder) setResponseEnvelope(envelope ResponseEnvelope, envelopeType reflect.Type) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.responseEnvelope = envelope
+	b.responseEnvType = envelopeType
+
+	// Rewrap success responses that were already added
+	for _, success := range b.successResponses {
+		b.wrapSuccessResponse(success)
+	}
+}
+
+// addSuccessResponse records an operation's JSON success response, wrapping its
+// schema in the response envelope if one is set
+func (b *OpenAPIBuilder) addSuccessResponse(operation *Operation, cfg *routeConfig) {
+	resp := operation.Responses["200"]
+	if resp == nil || resp.Response == nil {
+		return
+	}
+	contentType := "application/json"
+	if cfg != nil && cfg.responseContentType != "" {
+		contentType = cfg.responseContentType
+	}
+	mediaType, ok := resp.Response.Content[contentType]
+	if !ok {
+		return
+	}
+	success := successResponse{response: resp.Response, contentType: contentType, schema: mediaType.Schema, example: mediaType.Example}
+	b.successResponses = append(b.successResponses, success)
+	b.wrapSuccessResponse(success)
+}
+
+// wrapSuccessResponse sets the documented schema of a success response to its
+// schema wrapped in the current envelope
+func (b *OpenAPIBuilder) wrapSuccessResponse(success successResponse) {
+	mediaType := success.response.Content[success.contentType]
+	mediaType.Schema = success.schema
+	mediaType.Example = success.example
+	if b.responseEnvelope != nil {
+		mediaType.Schema = b.envelopeSchema(b.responseEnvType, success.schema, isResponseEnvelopeField)
+		if success.example != nil {
+			mediaType.Example = b.responseEnvelope(success.example)
+		}
+	}
+	success.response.Content[success.contentType] = mediaType
+}
+
+// ensureErrorResponse adds a shared error response to components if missing
+func (b *OpenAPIBuilder) ensureErrorResponse(name string) *ResponseOrRef {
+	if _, exists := b.spec.Components.Responses[name]; !exists {
+		component := errorResponseComponents[name]
+		contentType := "application/json"
+		schema := component.schema()
+		if b.problemDetails {
+			contentType = "application/problem+json"
+			schema = problemDetailsSchema()
+		} else if b.errorEnvelope != nil {
+			schema = b.envelopeSchema(b.errorEnvelope, schema, isErrorEnvelopeField)
+		}
+		b.spec.Components.Responses[name] = &Response{
+			Description: component.description,
 			Content: map[string]MediaType{
-				"application/json": {
-					Schema: &Schema{
-						Type: "object",
-						Properties: map[string]*Schema{
-							"code":    {Type: "string"},
-							"message": {Type: "string"},
-						},
-					},
+				contentType: {
+					Schema: schema,
 				},
 			},
 		}
 	}
-	// Use Ref type for reference
-	operation.Responses["500"] = &Ref{Ref: "#/components/responses/InternalError"}
+	return &ResponseOrRef{Ref: "#/components/responses/" + name}
+}
+
+// isResponseEnvelopeField reports whether an envelope field holds the handler's
+// response, which it marks with an envelope:"response" tag
+func isResponseEnvelopeField(field reflect.StructField) bool {
+	return field.Tag.Get("envelope") == "response"
+}
+
+// isErrorEnvelopeField reports whether an envelope field holds the ErrorResponse
+func isErrorEnvelopeField(field reflect.StructField) bool {
+	t := field.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t == reflect.TypeOf(ErrorResponse{})
+}
+
+// envelopeSchema derives the schema of an envelope type, substituting inner for
+// the fields holding the wrapped value
+func (b *OpenAPIBuilder) envelopeSchema(t reflect.Type, inner *Schema, isInner func(reflect.StructField) bool) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == reflect.TypeOf(time.Time{}) {
+		return b.createSchemaFromType(t, "")
+	}
+
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		jsonTag := field.Tag.Get("json")
+		if field.PkgPath != "" || jsonTag == "-" {
+			continue
+		}
+		fieldName := strings.Split(jsonTag, ",")[0]
+		if fieldName == "" {
+			fieldName = field.Name
+		}
+		if isInner(field) {
+			schema.Properties[fieldName] = inner
+		} else {
+			schema.Properties[fieldName] = b.envelopeSchema(field.Type, inner, isInner)
+		}
+	}
+	return schema
+}
+
+// addErrorResponses adds common error responses
+func (b *OpenAPIBuilder) addErrorResponses(operation *Operation) {
+	// Add validation error response (400 Bad Request by default)
+	operation.Responses[strconv.Itoa(b.validationStatus)] = b.ensureErrorResponse("ValidationError")
+
+	// Add 401 Unauthorized if security is required
+	if len(operation.Security) > 0 {
+		operation.Responses["401"] = b.ensureErrorResponse("UnauthorizedError")
+	}
+
+	// Add 500 Internal Server Error
+	operation.Responses["500"] = b.ensureErrorResponse("InternalError")
 }
 
-// GetSpec returns the built OpenAPI spec
+// GetSpec returns the built OpenAPI spec. Operation filters and parameter reuse are
+// applied to a copy on every call, so they see operations in their final form and
+// their changes are never applied twice.
 func (b *OpenAPIBuilder) GetSpec() *OpenAPISpec {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
-	return b.spec
+	if len(b.operationFilters) == 0 && b.paramReuseMin <= 0 {
+		return b.spec
+	}
+
+	spec := deepCopy(reflect.ValueOf(b.spec)).Interface().(*OpenAPISpec)
+	for _, path := range slices.Sorted(maps.Keys(spec.Paths)) {
+		spec.Paths[path].eachOperation(func(method string, operation *Operation) {
+			for _, filter := range b.operationFilters {
+				filter(method, path, operation)
+			}
+		})
+	}
+	b.hoistParameters(spec)
+	return spec
+}
+
+// deepCopy copies v, following pointers, maps, slices and interfaces, so a spec can be
+// modified without changing the one it was copied from
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(deepCopy(v.Elem()))
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if c.Field(i).CanSet() {
+				c.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopy(v.Elem()))
+		return c
+	default:
+		return v
+	}
 }
 
 // Helper functions
@@ -694,6 +2005,36 @@ func toCamelCase(s string) string {
 	return strings.Join(words, "")
 }
 
+// toSnakeCase converts a Go identifier to snake_case, keeping acronyms together
+func toSnakeCase(s string) string {
+	runes := []rune(s)
+	var out []rune
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			prevLower := i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]))
+			acronymEnd := i > 0 && unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || acronymEnd {
+				out = append(out, '_')
+			}
+			r = unicode.ToLower(r)
+		}
+		out = append(out, r)
+	}
+	return string(out)
+}
+
+// toLowerCamelCase lowercases the leading word of a Go identifier, e.g. HTTPServer -> httpServer
+func toLowerCamelCase(s string) string {
+	runes := []rune(s)
+	for i := 0; i < len(runes) && unicode.IsUpper(runes[i]); i++ {
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
+
 func parseIntConstraint(s string) *int {
 	var val int
 	if _, err := fmt.Sscanf(s, "%d", &val); err == nil {
@@ -702,27 +2043,210 @@ func parseIntConstraint(s string) *int {
 	return nil
 }
 
+// isRequiredRule reports whether a validate tag makes a field unconditionally required.
+// Conditional rules such as required_if are described by fieldDescription instead.
+func isRequiredRule(validateTag string) bool {
+	for _, rule := range strings.Split(validateTag, ",") {
+		if rule == "required" {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldDescription returns a field's description tag, annotated with the conditions
+// of any required_if, required_unless, required_with(out)(_all) rules, which JSON
+// Schema can't express directly
+func fieldDescription(parent reflect.Type, field reflect.StructField) string {
+	description := field.Tag.Get("description")
+	var conditions []string
+	for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		args := strings.Fields(param)
+		for i, arg := range args {
+			if name != "required_if" && name != "required_unless" || i%2 == 0 {
+				args[i] = documentedFieldName(parent, arg)
+			}
+		}
+		var condition string
+		switch name {
+		case "required_if", "required_unless":
+			var pairs []string
+			for i := 0; i+1 < len(args); i += 2 {
+				pairs = append(pairs, fmt.Sprintf("%s is %s", args[i], args[i+1]))
+			}
+			verb := "when"
+			if name == "required_unless" {
+				verb = "unless"
+			}
+			condition = fmt.Sprintf("Required %s %s.", verb, strings.Join(pairs, " and "))
+		case "required_with":
+			condition = fmt.Sprintf("Required when any of %s is present.", strings.Join(args, ", "))
+		case "required_with_all":
+			condition = fmt.Sprintf("Required when all of %s are present.", strings.Join(args, ", "))
+		case "required_without":
+			condition = fmt.Sprintf("Required when any of %s is absent.", strings.Join(args, ", "))
+		case "required_without_all":
+			condition = fmt.Sprintf("Required when all of %s are absent.", strings.Join(args, ", "))
+		default:
+			continue
+		}
+		conditions = append(conditions, condition)
+	}
+	if len(conditions) == 0 {
+		return description
+	}
+	if description != "" && !strings.HasSuffix(description, ".") {
+		description += "."
+	}
+	return strings.TrimSpace(description + " " + strings.Join(conditions, " "))
+}
+
+// documentedFieldName maps a Go field name referenced by a validation rule to the
+// name clients see in the request
+func documentedFieldName(parent reflect.Type, name string) string {
+	field, ok := parent.FieldByName(name)
+	if !ok {
+		return name
+	}
+	for _, tag := range []string{"json", "query", "header", "path"} {
+		if value := strings.Split(field.Tag.Get(tag), ",")[0]; value != "" && value != "-" {
+			return value
+		}
+	}
+	return name
+}
+
+// composeExample assembles an example value from the examples documented on a schema
+// and its properties, descending into nested objects, arrays and referenced schemas.
+// It returns nil if no examples are documented.
+func (b *OpenAPIBuilder) composeExample(schema *Schema, depth int) interface{} {
+	if schema == nil || depth > maxExampleDepth {
+		return nil
+	}
+	if schema.Example != nil {
+		return coerceExample(schema.Example, schema)
+	}
+	if schema.Ref != "" {
+		return b.composeExample(b.spec.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")], depth+1)
+	}
+
+	if len(schema.AllOf) > 0 {
+		var object map[string]interface{}
+		for _, part := range schema.AllOf {
+			if partObject, ok := b.composeExample(part, depth+1).(map[string]interface{}); ok {
+				if object == nil {
+					object = make(map[string]interface{})
+				}
+				maps.Copy(object, partObject)
+			}
+		}
+		if object == nil {
+			return nil
+		}
+		return object
+	}
+
+	// Unions are exemplified by their first variant, with its discriminator value
+	if len(schema.OneOf) > 0 {
+		example := b.composeExample(schema.OneOf[0], depth+1)
+		if schema.Discriminator == nil {
+			return example
+		}
+		object, _ := example.(map[string]interface{})
+		for _, value := range slices.Sorted(maps.Keys(schema.Discriminator.Mapping)) {
+			if schema.Discriminator.Mapping[value] == schema.OneOf[0].Ref {
+				if object == nil {
+					object = make(map[string]interface{})
+				}
+				object[schema.Discriminator.PropertyName] = value
+				break
+			}
+		}
+		if object == nil {
+			return nil
+		}
+		return object
+	}
+
+	switch schema.Type {
+	case "object":
+		var object map[string]interface{}
+		for name, property := range schema.Properties {
+			if value := b.composeExample(property, depth+1); value != nil {
+				if object == nil {
+					object = make(map[string]interface{})
+				}
+				object[name] = value
+			}
+		}
+		if object == nil {
+			return nil
+		}
+		return object
+	case "array":
+		if item := b.composeExample(schema.Items, depth+1); item != nil {
+			return []interface{}{item}
+		}
+	}
+	return nil
+}
+
+// coerceExample converts an example given as a string, e.g. from an example tag, to
+// the schema's JSON type, so "123" on an integer becomes 123 and a JSON array literal
+// on an array becomes an array. Values that don't parse are kept as strings.
+func coerceExample(example interface{}, schema *Schema) interface{} {
+	s, ok := example.(string)
+	if !ok || schema.Type == "string" {
+		return example
+	}
+	var value interface{}
+	if err := json.Unmarshal([]byte(s), &value); err != nil {
+		return example
+	}
+	return value
+}
+
+// parseValue converts a default or example tag value to the field's JSON type, so
+// example:"123" on an int is emitted as 123. Slices, maps, structs and interface{}
+// fields accept JSON literals. Values that don't parse as the type are kept as strings.
 func parseValue(s string, t reflect.Type) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
 	switch t.Kind() {
 	case reflect.Bool:
-		return s == "true"
-	case reflect.String:
-		return s
-	case reflect.Int, reflect.Int32, reflect.Int64:
-		var val int
-		fmt.Sscanf(s, "%d", &val)
-		return val
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, err := strconv.ParseUint(s, 10, 64); err == nil {
+			return n
+		}
 	case reflect.Float32, reflect.Float64:
-		var val float64
-		fmt.Sscanf(s, "%f", &val)
-		return val
-	default:
-		return s
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct, reflect.Interface:
+		var value interface{}
+		if json.Unmarshal([]byte(s), &value) == nil {
+			return value
+		}
 	}
+	return s
 }
 
 // AddSSERoute adds an SSE route to the OpenAPI spec
 func (b *OpenAPIBuilder) AddSSERoute(method, path string, handler *SSECompiledHandler, dependencies []string) {
+	b.addSSERoute(method, path, handler, dependencies, nil)
+}
+
+// addSSERoute adds an SSE route to the OpenAPI spec, applying route options
+func (b *OpenAPIBuilder) addSSERoute(method, path string, handler *SSECompiledHandler, dependencies []string, cfg *routeConfig) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
@@ -738,14 +2262,10 @@ func (b *OpenAPIBuilder) AddSSERoute(method, path string, handler *SSECompiledHa
 
 	// Create operation for SSE
 	operation := b.createSSEOperation(method, openAPIPath, handler, dependencies)
+	b.applyRouteConfig(operation, cfg, dependencies)
 
 	// Set operation on path item
-	switch strings.ToUpper(method) {
-	case http.MethodGet:
-		pathItem.Get = operation
-	case http.MethodPost:
-		pathItem.Post = operation
-	}
+	pathItem.setOperation(method, operation)
 }
 
 // createSSEOperation creates an OpenAPI operation for SSE endpoints
@@ -753,18 +2273,11 @@ func (b *OpenAPIBuilder) createSSEOperation(method, path string, handler *SSECom
 	operation := &Operation{
 		OperationID: generateOperationID(method, path) + "Stream",
 		Parameters:  []Parameter{},
-		Responses:   make(map[string]interface{}),
+		Responses:   make(map[string]*ResponseOrRef),
 	}
 
-	// Add security requirements if there are dependencies
-	if len(dependencies) > 0 {
-		operation.Security = []map[string][]string{}
-		for _, dep := range dependencies {
-			operation.Security = append(operation.Security, map[string][]string{
-				dep: {},
-			})
-		}
-	}
+	// Add security requirements for dependencies with security schemes
+	operation.Security = b.securityRequirements(dependencies, nil)
 
 	// Extract parameters from request type (same logic as regular routes)
 	var requestBodySchema *Schema
@@ -779,9 +2292,12 @@ func (b *OpenAPIBuilder) createSSEOperation(method, path string, handler *SSECom
 		}
 
 		// Handle parameters (same as regular routes)
-		if pathTag := field.Tag.Get("path"); pathTag != "" {
-			validateTag := field.Tag.Get("validate")
-			description := field.Tag.Get("description")
+		if sourceTag := field.Tag.Get("source"); sourceTag != "" {
+			operation.Parameters = append(operation.Parameters, b.createSourceParameters(field, sourceTag)...)
+		} else if _, ok := field.Tag.Lookup("in"); ok {
+			operation.Parameters = append(operation.Parameters, b.createQueryStructParameters(field.Type)...)
+		} else if pathTag := b.tags.tagFor(field, "path"); pathTag != "" {
+			description := fieldDescription(handler.reqType, field)
 			example := field.Tag.Get("example")
 
 			param := Parameter{
@@ -789,39 +2305,18 @@ func (b *OpenAPIBuilder) createSSEOperation(method, path string, handler *SSECom
 				In:          "path",
 				Required:    true,
 				Description: description,
-				Schema:      b.createSchemaFromType(field.Type, validateTag),
-			}
-			if example != "" {
-				param.Example = example
-			}
-			operation.Parameters = append(operation.Parameters, param)
-		} else if queryTag := field.Tag.Get("query"); queryTag != "" {
-			validateTag := field.Tag.Get("validate")
-			isRequired := strings.Contains(validateTag, "required")
-			description := field.Tag.Get("description")
-			example := field.Tag.Get("example")
-			defaultValue := field.Tag.Get("default")
-
-			schema := b.createSchemaFromType(field.Type, validateTag)
-			if defaultValue != "" {
-				schema.Default = parseValue(defaultValue, field.Type)
-			}
-
-			param := Parameter{
-				Name:        queryTag,
-				In:          "query",
-				Required:    isRequired,
-				Description: description,
-				Schema:      schema,
+				Schema:      b.createFieldSchema(field),
 			}
 			if example != "" {
-				param.Example = example
+				param.Example = parseValue(example, field.Type)
 			}
 			operation.Parameters = append(operation.Parameters, param)
-		} else if headerTag := field.Tag.Get("header"); headerTag != "" {
+		} else if queryTag := b.tags.tagFor(field, "query"); queryTag != "" {
+			operation.Parameters = append(operation.Parameters, b.createQueryParameter(handler.reqType, field, queryTag))
+		} else if headerTag := b.tags.tagFor(field, "header"); headerTag != "" {
 			validateTag := field.Tag.Get("validate")
-			isRequired := strings.Contains(validateTag, "required")
-			description := field.Tag.Get("description")
+			isRequired := isRequiredRule(validateTag)
+			description := fieldDescription(handler.reqType, field)
 			example := field.Tag.Get("example")
 
 			param := Parameter{
@@ -829,13 +2324,16 @@ func (b *OpenAPIBuilder) createSSEOperation(method, path string, handler *SSECom
 				In:          "header",
 				Required:    isRequired,
 				Description: description,
-				Schema:      b.createSchemaFromType(field.Type, validateTag),
+				Schema:      b.createFieldSchema(field),
 			}
 			if example != "" {
-				param.Example = example
+				param.Example = parseValue(example, field.Type)
 			}
+			useListStyle(&param)
 			operation.Parameters = append(operation.Parameters, param)
-		} else if jsonTag := field.Tag.Get("json"); jsonTag != "" && jsonTag != "-" {
+		} else if _, ok := field.Tag.Lookup("stream"); ok && isRecordStreamType(field.Type) {
+			operation.RequestBody = b.createStreamRequestBody(field)
+		} else if jsonTag := b.tags.tagFor(field, "body"); jsonTag != "" && jsonTag != "-" {
 			// Handle request body for POST SSE endpoints
 			if requestBodySchema == nil {
 				requestBodySchema = &Schema{
@@ -846,24 +2344,23 @@ func (b *OpenAPIBuilder) createSSEOperation(method, path string, handler *SSECom
 			}
 
 			validateTag := field.Tag.Get("validate")
-			isRequired := strings.Contains(validateTag, "required")
-			description := field.Tag.Get("description")
+			isRequired := isRequiredRule(validateTag)
+			description := fieldDescription(handler.reqType, field)
 			example := field.Tag.Get("example")
 			defaultValue := field.Tag.Get("default")
 
 			fieldName := strings.Split(jsonTag, ",")[0]
-			fieldSchema := b.createSchemaFromType(field.Type, validateTag)
+			fieldSchema := b.createFieldSchema(field)
 			fieldSchema.Description = description
 			if example != "" {
-				fieldSchema.Example = example
+				fieldSchema.Example = parseValue(example, field.Type)
 			}
 			if defaultValue != "" {
 				fieldSchema.Default = parseValue(defaultValue, field.Type)
 			}
 
-			requestBodySchema.Properties[fieldName] = fieldSchema
-			if isRequired {
-				requestBodyRequired = append(requestBodyRequired, fieldName)
+			if top := addBodyProperty(requestBodySchema, fieldName, fieldSchema, isRequired); top != "" && !slices.Contains(requestBodyRequired, top) {
+				requestBodyRequired = append(requestBodyRequired, top)
 			}
 		}
 	}
@@ -875,15 +2372,20 @@ func (b *OpenAPIBuilder) createSSEOperation(method, path string, handler *SSECom
 			Required: len(requestBodyRequired) > 0,
 			Content: map[string]MediaType{
 				"application/json": {
-					Schema: requestBodySchema,
+					Schema:  requestBodySchema,
+					Example: b.composeExample(requestBodySchema, 0),
 				},
 			},
 		}
 	}
 
 	// Create event data schema reference
-	eventDataSchema := b.getOrCreateSchema(handler.respType)
-	sseEventSchemaName := handler.respType.Name() + "SSEEvent"
+	eventDataSchema := b.createResponseSchema(handler.respType)
+	eventDataName := strings.TrimPrefix(eventDataSchema.Ref, "#/components/schemas/")
+	if eventDataName == "" {
+		eventDataName = sanitizeSchemaName(handler.respType.String())
+	}
+	sseEventSchemaName := eventDataName + "SSEEvent"
 	sseEventSchema := &Schema{
 		Type:        "object",
 		Description: "Server-Sent Event structure",
@@ -930,7 +2432,7 @@ func (b *OpenAPIBuilder) createSSEOperation(method, path string, handler *SSECom
 	}
 
 	// Assign the complete response
-	operation.Responses["200"] = sseResponse
+	operation.Responses["200"] = inlineResponse(sseResponse)
 
 	// Add common error responses
 	b.addErrorResponses(operation)
@@ -0,0 +1,194 @@
+package gofastapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/priyanshu-shubham/gofastapi"
+)
+
+// Error has the same name as gofastapi.Error
+type Error struct {
+	Reason string `json:"reason"`
+}
+
+func responseRef(t *testing.T, spec *gofastapi.OpenAPISpec, path string) string {
+	t.Helper()
+	op := spec.Paths[path].Get
+	if op == nil {
+		t.Fatalf("no GET operation for %s", path)
+	}
+	return op.Responses["200"].Response.Content["application/json"].Schema.Ref
+}
+
+func TestSchemaNamesOfSameNamedTypes(t *testing.T) {
+	r := gofastapi.New()
+	err := r.GET("/framework", func(ctx context.Context, req struct{}) (gofastapi.Error, error) {
+		return gofastapi.Error{}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.GET("/local", func(ctx context.Context, req struct{}) (Error, error) {
+		return Error{}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := r.GenerateOpenAPISpec()
+	frameworkRef := responseRef(t, spec, "/framework")
+	localRef := responseRef(t, spec, "/local")
+	if frameworkRef != "#/components/schemas/Error" {
+		t.Errorf("gofastapi.Error ref = %q, want #/components/schemas/Error", frameworkRef)
+	}
+	if localRef != "#/components/schemas/gofastapi_test.Error" {
+		t.Errorf("gofastapi_test.Error ref = %q, want #/components/schemas/gofastapi_test.Error", localRef)
+	}
+	if _, ok := spec.Components.Schemas["Error"].Properties["message"]; !ok {
+		t.Errorf("Error schema = %+v, want gofastapi.Error's properties", spec.Components.Schemas["Error"])
+	}
+	if _, ok := spec.Components.Schemas["gofastapi_test.Error"].Properties["reason"]; !ok {
+		t.Errorf("gofastapi_test.Error schema = %+v, want the local Error's properties", spec.Components.Schemas["gofastapi_test.Error"])
+	}
+}
+
+type Comment struct {
+	Text    string    `json:"text"`
+	Replies []Comment `json:"replies"`
+}
+
+func TestRecursiveSchema(t *testing.T) {
+	r := gofastapi.New()
+	err := r.GET("/comments", func(ctx context.Context, req struct{}) (Comment, error) {
+		return Comment{}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := r.GenerateOpenAPISpec()
+	if ref := responseRef(t, spec, "/comments"); ref != "#/components/schemas/Comment" {
+		t.Fatalf("response ref = %q, want #/components/schemas/Comment", ref)
+	}
+	replies := spec.Components.Schemas["Comment"].Properties["replies"]
+	if replies == nil || replies.Type != "array" || replies.Items == nil {
+		t.Fatalf("replies schema = %+v, want an array", replies)
+	}
+	if replies.Items.Ref != "#/components/schemas/Comment" {
+		t.Errorf("replies items ref = %q, want #/components/schemas/Comment", replies.Items.Ref)
+	}
+	if _, err := json.Marshal(spec); err != nil {
+		t.Errorf("marshal spec: %v", err)
+	}
+}
+
+type pageQuery struct {
+	Page int `query:"page"`
+}
+
+func TestReusedParametersKeepNameAndIn(t *testing.T) {
+	r := gofastapi.New()
+	r.ReuseParameters(2)
+	for _, path := range []string{"/users", "/posts"} {
+		err := r.GET(path, func(ctx context.Context, req pageQuery) (string, error) {
+			return "", nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	spec := r.GenerateOpenAPISpec()
+	param := spec.Paths["/users"].Get.Parameters[0]
+	if param.Ref != "#/components/parameters/page" {
+		t.Fatalf("parameter = %+v, want a reference to components.parameters.page", param)
+	}
+	if param.Name != "page" || param.In != "query" {
+		t.Errorf("referenced parameter name/in = %q/%q, want page/query", param.Name, param.In)
+	}
+	data, err := json.Marshal(param)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"$ref":"#/components/parameters/page"}` {
+		t.Errorf("marshaled parameter = %s, want only $ref", data)
+	}
+}
+
+func TestOperationFiltersRunBeforeParameterReuse(t *testing.T) {
+	r := gofastapi.New()
+	r.ReuseParameters(2)
+	var sawStatus bool
+	r.AddOperationFilter(func(method, path string, op *gofastapi.Operation) {
+		op.Parameters = append(op.Parameters, gofastapi.Parameter{
+			Name: "X-Tenant-ID", In: "header", Schema: &gofastapi.Schema{Type: "string"},
+		})
+		_, sawStatus = op.Responses["422"]
+	})
+	for _, path := range []string{"/users", "/posts"} {
+		err := r.GET(path, func(ctx context.Context, req pageQuery) (string, error) {
+			return "", nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Changes made after the filter was added are visible to it
+	r.SetValidationErrorStatus(422)
+
+	for i := 0; i < 2; i++ {
+		spec := r.GenerateOpenAPISpec()
+		params := spec.Paths["/users"].Get.Parameters
+		if len(params) != 2 {
+			t.Fatalf("call %d: parameters = %+v, want page and X-Tenant-ID once each", i, params)
+		}
+		if params[1].Ref != "#/components/parameters/X-Tenant-ID" || params[1].Name != "X-Tenant-ID" {
+			t.Errorf("call %d: filter-added parameter = %+v, want it hoisted", i, params[1])
+		}
+		if !sawStatus {
+			t.Errorf("call %d: filter ran before SetValidationErrorStatus took effect", i)
+		}
+	}
+}
+
+type dataEnvelope struct {
+	Data    interface{} `json:"data" envelope:"response"`
+	Version string      `json:"version"`
+}
+
+type errorEnvelope struct {
+	Error gofastapi.ErrorResponse `json:"error"`
+}
+
+func TestEnvelopeSchemasFollowDeclaredTypes(t *testing.T) {
+	r := gofastapi.New()
+	gofastapi.SetResponseEnvelope(r, func(resp interface{}) dataEnvelope {
+		return dataEnvelope{Data: resp, Version: "1"}
+	})
+	gofastapi.SetErrorEnvelope(r, func(resp gofastapi.ErrorResponse) errorEnvelope {
+		return errorEnvelope{Error: resp}
+	})
+	err := r.GET("/comments", func(ctx context.Context, req struct{}) (Comment, error) {
+		return Comment{}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := r.GenerateOpenAPISpec()
+	success := spec.Paths["/comments"].Get.Responses["200"].Response.Content["application/json"].Schema
+	if data := success.Properties["data"]; data == nil || data.Ref != "#/components/schemas/Comment" {
+		t.Errorf("data schema = %+v, want a reference to Comment", data)
+	}
+	if version := success.Properties["version"]; version == nil || version.Type != "string" {
+		t.Errorf("version schema = %+v, want a string", version)
+	}
+
+	validation := spec.Components.Responses["ValidationError"].Content["application/json"].Schema
+	inner := validation.Properties["error"]
+	if inner == nil || inner.Properties["validation_errors"] == nil {
+		t.Errorf("error schema = %+v, want the validation error schema", inner)
+	}
+}
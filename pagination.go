@@ -0,0 +1,72 @@
+package gofastapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Page is a paginated list response. Handlers returning a Page also get an RFC 5988
+// Link header with first, prev, next and last links, built from the request URL by
+// replacing its page query parameter.
+type Page[T any] struct {
+	Items []T `json:"items"`
+	Total int `json:"total" description:"Total number of items across all pages"`
+	Page  int `json:"page" description:"1-based page number"`
+	Size  int `json:"size" description:"Maximum number of items per page"`
+}
+
+// NewPage returns page number page of size items out of total
+func NewPage[T any](items []T, total, page, size int) Page[T] {
+	if items == nil {
+		items = []T{} // Encode an empty page as [] rather than null
+	}
+	return Page[T]{Items: items, Total: total, Page: page, Size: size}
+}
+
+// LastPage returns the number of the last page, at least 1
+func (p Page[T]) LastPage() int {
+	if p.Size <= 0 || p.Total <= 0 {
+		return 1
+	}
+	return (p.Total + p.Size - 1) / p.Size
+}
+
+func (p Page[T]) pageInfo() (page, last int) {
+	return p.Page, p.LastPage()
+}
+
+// pager is implemented by Page for any item type
+type pager interface {
+	pageInfo() (page, last int)
+}
+
+// applyPageLinks sets the Link header of a Page response from the request URL
+func applyPageLinks(h http.Header, r *http.Request, resp interface{}, pageParam string) {
+	p, ok := resp.(pager)
+	if !ok {
+		return
+	}
+	page, last := p.pageInfo()
+	if page < 1 {
+		page = 1
+	}
+
+	link := func(n int, rel string) string {
+		query := r.URL.Query()
+		query.Set(pageParam, strconv.Itoa(n))
+		u := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+		return fmt.Sprintf("<%s>; rel=%q", u.String(), rel)
+	}
+	links := []string{link(1, "first")}
+	if page > 1 {
+		links = append(links, link(min(page-1, last), "prev"))
+	}
+	if page < last {
+		links = append(links, link(page+1, "next"))
+	}
+	links = append(links, link(last, "last"))
+	h.Set("Link", strings.Join(links, ", "))
+}
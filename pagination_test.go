@@ -0,0 +1,59 @@
+package gofastapi
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestPageLinks(t *testing.T) {
+	r := New()
+	r.SetPageParam("p")
+	err := r.GET("/items", func(ctx context.Context, req struct {
+		Page int `query:"p"`
+		Size int `query:"size"`
+	}) (Page[string], error) {
+		return NewPage[string](nil, 35, req.Page, req.Size), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"p=2&size=10", `</items?p=1&size=10>; rel="first", </items?p=1&size=10>; rel="prev", </items?p=3&size=10>; rel="next", </items?p=4&size=10>; rel="last"`},
+		{"p=1&size=10", `</items?p=1&size=10>; rel="first", </items?p=2&size=10>; rel="next", </items?p=4&size=10>; rel="last"`},
+		{"p=9&size=10", `</items?p=1&size=10>; rel="first", </items?p=4&size=10>; rel="prev", </items?p=4&size=10>; rel="last"`},
+	}
+	for _, tt := range tests {
+		resp, err := r.TestRequest(http.MethodGet, "/items?"+tt.query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := resp.Header.Get("Link"); got != tt.want {
+			t.Errorf("?%s: Link = %s, want %s", tt.query, got, tt.want)
+		}
+		if !strings.Contains(string(resp.Body), `"items":[]`) {
+			t.Errorf("?%s: body = %s, want an empty items array", tt.query, resp.Body)
+		}
+	}
+}
+
+func TestPageLastPage(t *testing.T) {
+	tests := []struct {
+		total, size, want int
+	}{
+		{35, 10, 4},
+		{40, 10, 4},
+		{0, 10, 1},
+		{5, 0, 1},
+	}
+	for _, tt := range tests {
+		if got := NewPage([]int{}, tt.total, 1, tt.size).LastPage(); got != tt.want {
+			t.Errorf("LastPage of %d items by %d = %d, want %d", tt.total, tt.size, got, tt.want)
+		}
+	}
+}
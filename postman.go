@@ -0,0 +1,397 @@
+package gofastapi
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// postmanSchemaURL identifies the Postman collection format version
+const postmanSchemaURL = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// maxExampleDepth bounds example generation for deeply nested or recursive schemas
+const maxExampleDepth = 6
+
+type postmanCollection struct {
+	Info     postmanInfo       `json:"info"`
+	Item     []*postmanItem    `json:"item"`
+	Variable []postmanVariable `json:"variable,omitempty"`
+}
+
+type postmanInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Schema      string `json:"schema"`
+}
+
+type postmanItem struct {
+	Name    string          `json:"name"`
+	Item    []*postmanItem  `json:"item,omitempty"`    // Folder contents
+	Request *postmanRequest `json:"request,omitempty"` // Set for requests
+}
+
+type postmanRequest struct {
+	Method      string            `json:"method"`
+	Description string            `json:"description,omitempty"`
+	Header      []postmanKeyValue `json:"header"`
+	URL         postmanURL        `json:"url"`
+	Body        *postmanBody      `json:"body,omitempty"`
+	Auth        *postmanAuth      `json:"auth,omitempty"`
+}
+
+type postmanURL struct {
+	Raw      string            `json:"raw"`
+	Host     []string          `json:"host"`
+	Path     []string          `json:"path"`
+	Query    []postmanKeyValue `json:"query,omitempty"`
+	Variable []postmanKeyValue `json:"variable,omitempty"`
+}
+
+type postmanKeyValue struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
+}
+
+type postmanVariable struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+}
+
+type postmanBody struct {
+	Mode    string                 `json:"mode"`
+	Raw     string                 `json:"raw"`
+	Options map[string]interface{} `json:"options,omitempty"`
+}
+
+type postmanAuth struct {
+	Type   string            `json:"type"`
+	Bearer []postmanKeyValue `json:"bearer,omitempty"`
+	Basic  []postmanKeyValue `json:"basic,omitempty"`
+	APIKey []postmanKeyValue `json:"apikey,omitempty"`
+}
+
+// PostmanCollection exports the routes as a Postman v2.1 collection. Operations are
+// grouped into folders by their first tag, request bodies and parameters are filled
+// from documented examples (or generated from schemas), and the first configured
+// server becomes the {{baseUrl}} variable.
+func (r *Router) PostmanCollection() ([]byte, error) {
+	spec := r.GenerateOpenAPISpec()
+	r.openAPIBuilder.mu.RLock()
+	defer r.openAPIBuilder.mu.RUnlock()
+	return json.MarshalIndent(buildPostmanCollection(spec), "", "  ")
+}
+
+// ServePostmanCollection serves the Postman collection at the specified path
+func (r *Router) ServePostmanCollection(path string) {
+	r.mux.Handle(path, r.withMiddleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		collection, err := r.PostmanCollection()
+		if err != nil {
+			http.Error(w, "Failed to generate Postman collection", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(collection)
+	}), nil, nil)).Methods(http.MethodGet)
+}
+
+// buildPostmanCollection converts an OpenAPI spec into a Postman collection
+func buildPostmanCollection(spec *OpenAPISpec) *postmanCollection {
+	collection := &postmanCollection{
+		Info: postmanInfo{
+			Name:        spec.Info.Title,
+			Description: spec.Info.Description,
+			Schema:      postmanSchemaURL,
+		},
+		Item: []*postmanItem{},
+	}
+
+	// Servers become the base URL; extra servers are listed for switching
+	baseURL := postmanVariable{Key: "baseUrl", Value: "http://localhost:8080"}
+	if len(spec.Servers) > 0 {
+		baseURL.Value = spec.Servers[0].defaultURL()
+		baseURL.Description = spec.Servers[0].Description
+		var others []string
+		for _, server := range spec.Servers[1:] {
+			others = append(others, strings.TrimSpace(server.URL+" "+server.Description))
+		}
+		if len(others) > 0 {
+			baseURL.Description = strings.TrimSpace(baseURL.Description + " (other servers: " + strings.Join(others, ", ") + ")")
+		}
+	}
+	collection.Variable = append(collection.Variable, baseURL)
+
+	paths := make([]string, 0, len(spec.Paths))
+	for path := range spec.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	folders := make(map[string]*postmanItem)
+	for _, path := range paths {
+		pathItem := spec.Paths[path]
+		for _, entry := range []struct {
+			method    string
+			operation *Operation
+		}{
+			{http.MethodGet, pathItem.Get},
+			{http.MethodPost, pathItem.Post},
+			{http.MethodPut, pathItem.Put},
+			{http.MethodPatch, pathItem.Patch},
+			{http.MethodDelete, pathItem.Delete},
+			{http.MethodOptions, pathItem.Options},
+			{http.MethodHead, pathItem.Head},
+		} {
+			if entry.operation == nil {
+				continue
+			}
+			item := postmanRequestItem(spec, entry.method, path, entry.operation)
+			if len(entry.operation.Tags) == 0 {
+				collection.Item = append(collection.Item, item)
+				continue
+			}
+			tag := entry.operation.Tags[0]
+			folder, ok := folders[tag]
+			if !ok {
+				folder = &postmanItem{Name: tag}
+				folders[tag] = folder
+				collection.Item = append(collection.Item, folder)
+			}
+			folder.Item = append(folder.Item, item)
+		}
+	}
+
+	return collection
+}
+
+// postmanRequestItem converts a single operation into a Postman request
+func postmanRequestItem(spec *OpenAPISpec, method, path string, operation *Operation) *postmanItem {
+	name := operation.Summary
+	if name == "" {
+		name = method + " " + path
+	}
+	request := &postmanRequest{
+		Method:      method,
+		Description: operation.Description,
+		Header:      []postmanKeyValue{},
+		URL:         postmanURL{Host: []string{"{{baseUrl}}"}},
+	}
+
+	// Path segments use Postman's :name syntax for variables
+	var segments []string
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			segment = ":" + strings.Trim(segment, "{}")
+		}
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	request.URL.Path = segments
+
+	for _, param := range operation.Parameters {
+		if param.Ref != "" {
+			resolved, ok := spec.Components.Parameters[strings.TrimPrefix(param.Ref, "#/components/parameters/")]
+			if !ok {
+				continue
+			}
+			param = *resolved
+		}
+		entry := postmanKeyValue{
+			Key:         param.Name,
+			Value:       exampleString(parameterExample(spec, param)),
+			Description: param.Description,
+		}
+		switch param.In {
+		case "path":
+			request.URL.Variable = append(request.URL.Variable, entry)
+		case "query":
+			entry.Disabled = !param.Required
+			request.URL.Query = append(request.URL.Query, entry)
+		case "header":
+			request.Header = append(request.Header, entry)
+		}
+	}
+
+	if operation.RequestBody != nil {
+		for contentType, mediaType := range operation.RequestBody.Content {
+			request.Header = append(request.Header, postmanKeyValue{Key: "Content-Type", Value: contentType})
+			raw, _ := json.MarshalIndent(mediaTypeExample(spec, mediaType), "", "  ")
+			request.Body = &postmanBody{
+				Mode:    "raw",
+				Raw:     string(raw),
+				Options: map[string]interface{}{"raw": map[string]string{"language": "json"}},
+			}
+			break
+		}
+	}
+
+	request.Auth = postmanAuthFor(spec, operation)
+	request.URL.Raw = postmanRawURL(request.URL)
+	return &postmanItem{Name: name, Request: request}
+}
+
+// postmanRawURL renders the full URL string Postman displays
+func postmanRawURL(u postmanURL) string {
+	raw := "{{baseUrl}}/" + strings.Join(u.Path, "/")
+	var query []string
+	for _, q := range u.Query {
+		if !q.Disabled {
+			query = append(query, q.Key+"="+q.Value)
+		}
+	}
+	if len(query) > 0 {
+		raw += "?" + strings.Join(query, "&")
+	}
+	return raw
+}
+
+// postmanAuthFor maps the first security requirement to Postman auth settings,
+// using collection variables for the credentials
+func postmanAuthFor(spec *OpenAPISpec, operation *Operation) *postmanAuth {
+	if len(operation.Security) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(operation.Security[0]))
+	for name := range operation.Security[0] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		scheme, ok := spec.Components.SecuritySchemes[name]
+		if !ok {
+			continue
+		}
+		switch {
+		case scheme.Type == "http" && scheme.Scheme == "bearer":
+			return &postmanAuth{Type: "bearer", Bearer: []postmanKeyValue{{Key: "token", Value: "{{token}}"}}}
+		case scheme.Type == "http" && scheme.Scheme == "basic":
+			return &postmanAuth{Type: "basic", Basic: []postmanKeyValue{
+				{Key: "username", Value: "{{username}}"},
+				{Key: "password", Value: "{{password}}"},
+			}}
+		case scheme.Type == "apiKey":
+			return &postmanAuth{Type: "apikey", APIKey: []postmanKeyValue{
+				{Key: "key", Value: scheme.Name},
+				{Key: "value", Value: "{{apiKey}}"},
+				{Key: "in", Value: scheme.In},
+			}}
+		}
+	}
+	return nil
+}
+
+// parameterExample returns a parameter's documented example or one generated from its schema
+func parameterExample(spec *OpenAPISpec, param Parameter) interface{} {
+	if param.Example != nil {
+		return param.Example
+	}
+	if example, ok := firstExample(param.Examples); ok {
+		return example
+	}
+	for _, mediaType := range param.Content {
+		return mediaTypeExample(spec, mediaType)
+	}
+	if param.Schema == nil {
+		return ""
+	}
+	return schemaExample(spec, param.Schema, 0)
+}
+
+// mediaTypeExample returns a media type's documented example or one generated from its schema
+func mediaTypeExample(spec *OpenAPISpec, mediaType MediaType) interface{} {
+	if mediaType.Example != nil {
+		return mediaType.Example
+	}
+	if example, ok := firstExample(mediaType.Examples); ok {
+		return example
+	}
+	return schemaExample(spec, mediaType.Schema, 0)
+}
+
+// firstExample returns the value of the alphabetically first named example
+func firstExample(examples map[string]*Example) (interface{}, bool) {
+	names := make([]string, 0, len(examples))
+	for name := range examples {
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil, false
+	}
+	sort.Strings(names)
+	return examples[names[0]].Value, true
+}
+
+// schemaExample builds an example value from a schema, preferring documented
+// examples, defaults and enum values over placeholders
+func schemaExample(spec *OpenAPISpec, schema *Schema, depth int) interface{} {
+	if schema == nil || depth > maxExampleDepth {
+		return nil
+	}
+	if schema.Ref != "" {
+		return schemaExample(spec, spec.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")], depth+1)
+	}
+	switch {
+	case schema.Example != nil:
+		return schema.Example
+	case schema.Default != nil:
+		return schema.Default
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	case len(schema.OneOf) > 0:
+		return schemaExample(spec, schema.OneOf[0], depth+1)
+	case len(schema.AllOf) > 0:
+		object := make(map[string]interface{})
+		for _, part := range schema.AllOf {
+			if partObject, ok := schemaExample(spec, part, depth+1).(map[string]interface{}); ok {
+				maps.Copy(object, partObject)
+			}
+		}
+		return object
+	}
+
+	switch schema.Type {
+	case "object":
+		object := make(map[string]interface{}, len(schema.Properties))
+		for name, property := range schema.Properties {
+			object[name] = schemaExample(spec, property, depth+1)
+		}
+		return object
+	case "array":
+		return []interface{}{schemaExample(spec, schema.Items, depth+1)}
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return false
+	case "string":
+		switch schema.Format {
+		case "date-time":
+			return "2024-01-01T00:00:00Z"
+		case "uuid":
+			return "00000000-0000-0000-0000-000000000000"
+		case "email":
+			return "user@example.com"
+		case "duration":
+			return "1s"
+		}
+		return "string"
+	}
+	return nil
+}
+
+// exampleString renders an example value for a URL or header
+func exampleString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		raw, _ := json.Marshal(v)
+		return string(raw)
+	}
+}
@@ -0,0 +1,81 @@
+package gofastapi
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestPostmanCollection(t *testing.T) {
+	r := New()
+	err := r.GET("/users/{id}", func(ctx context.Context, req struct {
+		ID   string `path:"id"`
+		Page int    `query:"page"`
+	}) (string, error) {
+		return req.ID, nil
+	}, WithTags("users"), WithSummary("Get user"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.POST("/users", func(ctx context.Context, req struct {
+		Name string `json:"name"`
+	}) (string, error) {
+		return req.Name, nil
+	}, WithTags("users"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.GET("/health", func(ctx context.Context, req struct{}) (string, error) {
+		return "ok", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := r.PostmanCollection()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var collection postmanCollection
+	if err := json.Unmarshal(data, &collection); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(collection.Variable) == 0 || collection.Variable[0].Key != "baseUrl" {
+		t.Errorf("variables = %+v, want baseUrl first", collection.Variable)
+	}
+	items := make(map[string]*postmanItem)
+	for _, item := range collection.Item {
+		items[item.Name] = item
+	}
+	if health := items["GET /health"]; health == nil || health.Request == nil {
+		t.Errorf("top-level items = %v, want the untagged GET /health request", items)
+	}
+	folder := items["users"]
+	if folder == nil || len(folder.Item) != 2 {
+		t.Fatalf("users folder = %+v, want both tagged requests", folder)
+	}
+
+	for _, item := range folder.Item {
+		request := item.Request
+		switch item.Name {
+		case "Get user":
+			if got := strings.Join(request.URL.Path, "/"); got != "users/:id" {
+				t.Errorf("path = %q, want users/:id", got)
+			}
+			if len(request.URL.Variable) != 1 || request.URL.Variable[0].Key != "id" {
+				t.Errorf("path variables = %+v, want id", request.URL.Variable)
+			}
+			if len(request.URL.Query) != 1 || request.URL.Query[0].Key != "page" || !request.URL.Query[0].Disabled {
+				t.Errorf("query = %+v, want the optional page parameter, disabled", request.URL.Query)
+			}
+		case "POST /users":
+			if request.Body == nil || !strings.Contains(request.Body.Raw, `"name"`) {
+				t.Errorf("body = %+v, want an example with a name", request.Body)
+			}
+		default:
+			t.Errorf("unexpected request %q in the users folder", item.Name)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package gofastapi
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"sort"
+	"sync/atomic"
+)
+
+// ProblemDetails is an RFC 7807 problem details error response
+type ProblemDetails struct {
+	Type      string              `json:"type"`
+	Title     string              `json:"title"`
+	Status    int                 `json:"status"`
+	Detail    string              `json:"detail,omitempty"`
+	Instance  string              `json:"instance,omitempty"`
+	Code      string              `json:"code,omitempty"`
+	Details   map[string]string   `json:"details,omitempty"`
+	Errors    []ProblemFieldError `json:"errors,omitempty"`
+	RequestID string              `json:"request_id,omitempty"`
+}
+
+// ProblemFieldError is a validation failure in the errors extension of ProblemDetails
+type ProblemFieldError struct {
+	Field  string `json:"field"`
+	Detail string `json:"detail"`
+}
+
+// ProblemDetailsErrorHandler writes errors as RFC 7807 application/problem+json, with
+// validation failures in an errors extension array. Set it with
+// r.SetErrorHandler(gofastapi.ProblemDetailsErrorHandler) to also apply the router's
+// error mappings and document the problem+json schema in the OpenAPI spec.
+func ProblemDetailsErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	writeProblemDetails(w, r, err, slog.Default(), http.StatusBadRequest)
+}
+
+// isProblemDetailsHandler reports whether handler is ProblemDetailsErrorHandler
+func isProblemDetailsHandler(handler ErrorHandler) bool {
+	return handler != nil &&
+		reflect.ValueOf(handler).Pointer() == reflect.ValueOf(ProblemDetailsErrorHandler).Pointer()
+}
+
+// newProblemDetailsErrorHandler returns ProblemDetailsErrorHandler bound to the
+// router's error mappings and logger
+func newProblemDetailsErrorHandler(mappings *errorMappings, config *atomic.Pointer[routerConfig]) ErrorHandler {
+	return func(w http.ResponseWriter, r *http.Request, err error) {
+		cfg := config.Load()
+		writeProblemDetails(w, r, mappings.apply(err), cfg.log(), cfg.validationStatus)
+	}
+}
+
+// writeProblemDetails converts err to ProblemDetails and writes it as problem+json
+func writeProblemDetails(w http.ResponseWriter, r *http.Request, err error, logger *slog.Logger, validationStatus int) {
+	status, response := toErrorResponse(w, r, err, logger, validationStatus)
+	problem := ProblemDetails{
+		Type:      "about:blank",
+		Title:     statusTitle(status),
+		Status:    status,
+		Detail:    response.Message,
+		Instance:  r.URL.Path,
+		Code:      response.Code,
+		Details:   response.Details,
+		Errors:    problemFieldErrors(response.Fields),
+		RequestID: response.RequestID,
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// statusTitle returns the reason phrase of status, including non-standard ones
+func statusTitle(status int) string {
+	if status == StatusClientClosedRequest {
+		return "Client Closed Request"
+	}
+	return http.StatusText(status)
+}
+
+// problemFieldErrors flattens validation failures into one entry per message,
+// sorted by field
+func problemFieldErrors(fields map[string][]string) []ProblemFieldError {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []ProblemFieldError
+	for _, name := range names {
+		for _, detail := range fields[name] {
+			errs = append(errs, ProblemFieldError{Field: name, Detail: detail})
+		}
+	}
+	return errs
+}
+
+// problemDetailsSchema is the documented schema of ProblemDetails
+func problemDetailsSchema() *Schema {
+	return &Schema{
+		Type:     "object",
+		Required: []string{"type", "title", "status"},
+		Properties: map[string]*Schema{
+			"type":       {Type: "string", Format: "uri-reference"},
+			"title":      {Type: "string"},
+			"status":     {Type: "integer"},
+			"detail":     {Type: "string"},
+			"instance":   {Type: "string", Format: "uri-reference"},
+			"code":       {Type: "string"},
+			"details":    {Type: "object", AdditionalProperties: &Schema{Type: "string"}},
+			"request_id": {Type: "string"},
+			"errors": {
+				Type: "array",
+				Items: &Schema{
+					Type: "object",
+					Properties: map[string]*Schema{
+						"field":  {Type: "string"},
+						"detail": {Type: "string"},
+					},
+				},
+			},
+		},
+	}
+}
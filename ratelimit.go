@@ -0,0 +1,212 @@
+package gofastapi
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimitKeyFunc derives the key requests are limited by, e.g. the client IP
+type RateLimitKeyFunc func(r *http.Request) string
+
+// ClientIPKey keys requests by the connection's remote IP. Requests behind a proxy
+// all share the proxy's address; use a key function reading a trusted forwarding
+// header in that case.
+func ClientIPKey(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// RateLimitOption configures a RateLimiter
+type RateLimitOption func(*RateLimiter)
+
+// WithRateLimitKey sets the function requests are keyed by (default: ClientIPKey)
+func WithRateLimitKey(key RateLimitKeyFunc) RateLimitOption {
+	return func(l *RateLimiter) {
+		l.key = key
+	}
+}
+
+// WithRateLimitBurst sets how many requests a key may make at once (default: the limit)
+func WithRateLimitBurst(burst int) RateLimitOption {
+	return func(l *RateLimiter) {
+		l.burst = burst
+	}
+}
+
+// RateLimitRequest is the request type of RateLimiter.Handle
+type RateLimitRequest struct {
+	Request *http.Request `request:""`
+}
+
+// RateLimit is the quota state of a key. Injected as a dependency result, it sets the
+// X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset headers.
+type RateLimit struct {
+	Limit     int           `json:"limit"`
+	Remaining int           `json:"remaining"`
+	Reset     time.Duration `json:"-"` // Until the bucket is full again
+}
+
+// ApplyHeaders exposes the quota on the response
+func (l RateLimit) ApplyHeaders(h http.Header) {
+	h.Set("X-RateLimit-Limit", strconv.Itoa(l.Limit))
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(l.Remaining))
+	h.Set("X-RateLimit-Reset", strconv.Itoa(int(math.Ceil(l.Reset.Seconds()))))
+}
+
+// rateLimitError is the 429 returned when a key has no requests left
+type rateLimitError struct {
+	apiErr     *Error
+	limit      RateLimit
+	retryAfter time.Duration
+}
+
+func (e *rateLimitError) Error() string {
+	return e.apiErr.Message
+}
+
+// Unwrap exposes the API error so the response keeps its status
+func (e *rateLimitError) Unwrap() error {
+	return e.apiErr
+}
+
+// ApplyHeaders sets the quota headers and Retry-After
+func (e *rateLimitError) ApplyHeaders(h http.Header) {
+	e.limit.ApplyHeaders(h)
+	h.Set("Retry-After", strconv.Itoa(int(math.Ceil(e.retryAfter.Seconds()))))
+}
+
+// rateLimitShards spreads keys over independently locked maps to reduce contention
+const rateLimitShards = 32
+
+// RateLimiter is a dependency limiting each key to limit requests per period with a
+// token bucket, so quota refills continuously rather than at window boundaries. Keys
+// idle long enough for their bucket to refill are dropped automatically.
+//
+//	r.RegisterDependency("rate_limit", gofastapi.NewRateLimiter(100, time.Minute))
+//
+//	type CreatePostRequest struct {
+//	    RateLimit gofastapi.RateLimit `dep:"rate_limit"`
+//	}
+//
+// To limit by something only known to another dependency, such as the user ID, call
+// Allow from your own dependency instead.
+type RateLimiter struct {
+	rate   float64 // Tokens added per second
+	burst  int
+	key    RateLimitKeyFunc
+	shards [rateLimitShards]rateLimitShard
+	now    func() time.Time
+}
+
+type rateLimitShard struct {
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	mu        sync.Mutex
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a rate limiter allowing limit requests per period for each key.
+// Like time.NewTicker, it panics on a non-positive limit, period or burst, since no
+// requests could ever be allowed.
+func NewRateLimiter(limit int, per time.Duration, opts ...RateLimitOption) *RateLimiter {
+	if limit <= 0 {
+		panic(fmt.Sprintf("gofastapi: NewRateLimiter limit must be positive, got %d", limit))
+	}
+	if per <= 0 {
+		panic(fmt.Sprintf("gofastapi: NewRateLimiter period must be positive, got %v", per))
+	}
+	l := &RateLimiter{
+		rate:  float64(limit) / per.Seconds(),
+		burst: limit,
+		key:   ClientIPKey,
+		now:   time.Now,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if l.burst <= 0 {
+		panic(fmt.Sprintf("gofastapi: NewRateLimiter burst must be positive, got %d", l.burst))
+	}
+	if l.key == nil {
+		panic("gofastapi: NewRateLimiter key function must not be nil")
+	}
+	for i := range l.shards {
+		l.shards[i].buckets = make(map[string]*tokenBucket)
+	}
+	return l
+}
+
+// Handle takes a token for the request's key, failing with 429 when none are left
+func (l *RateLimiter) Handle(ctx context.Context, req RateLimitRequest) (RateLimit, error) {
+	return l.Allow(l.key(req.Request))
+}
+
+// Allow takes a token for key. When none are left it returns a 429 error that sets
+// Retry-After and the X-RateLimit-* headers.
+func (l *RateLimiter) Allow(key string) (RateLimit, error) {
+	shard := &l.shards[shardIndex(key)]
+	now := l.now()
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	// Buckets idle for a full refill are indistinguishable from new ones, so drop them
+	refill := time.Duration(float64(l.burst) / l.rate * float64(time.Second))
+	if now.Sub(shard.lastSweep) > refill {
+		for k, bucket := range shard.buckets {
+			if now.Sub(bucket.last) >= refill {
+				delete(shard.buckets, k)
+			}
+		}
+		shard.lastSweep = now
+	}
+
+	bucket, ok := shard.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(l.burst), last: now}
+		shard.buckets[key] = bucket
+	}
+	bucket.tokens = math.Min(float64(l.burst), bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate)
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		state := l.state(bucket)
+		return state, &rateLimitError{
+			apiErr:     NewErrorWithCode(http.StatusTooManyRequests, "RATE_LIMITED", "Rate limit exceeded"),
+			limit:      state,
+			retryAfter: time.Duration((1 - bucket.tokens) / l.rate * float64(time.Second)),
+		}
+	}
+	bucket.tokens--
+	return l.state(bucket), nil
+}
+
+// state reports a bucket's quota
+func (l *RateLimiter) state(bucket *tokenBucket) RateLimit {
+	return RateLimit{
+		Limit:     l.burst,
+		Remaining: int(bucket.tokens),
+		Reset:     time.Duration((float64(l.burst) - bucket.tokens) / l.rate * float64(time.Second)),
+	}
+}
+
+// shardIndex picks the shard holding key
+func shardIndex(key string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return h.Sum32() % rateLimitShards
+}
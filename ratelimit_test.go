@@ -0,0 +1,45 @@
+package gofastapi
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewRateLimiterRejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     int
+		per       time.Duration
+		opts      []RateLimitOption
+		wantPanic string
+	}{
+		{name: "zero limit", limit: 0, per: time.Minute, wantPanic: "limit must be positive"},
+		{name: "negative limit", limit: -1, per: time.Minute, wantPanic: "limit must be positive"},
+		{name: "zero period", limit: 10, per: 0, wantPanic: "period must be positive"},
+		{name: "zero burst", limit: 10, per: time.Minute, opts: []RateLimitOption{WithRateLimitBurst(0)}, wantPanic: "burst must be positive"},
+		{name: "nil key", limit: 10, per: time.Minute, opts: []RateLimitOption{WithRateLimitKey(nil)}, wantPanic: "key function must not be nil"},
+		{name: "valid", limit: 10, per: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				recovered := recover()
+				if tt.wantPanic == "" {
+					if recovered != nil {
+						t.Fatalf("unexpected panic: %v", recovered)
+					}
+					return
+				}
+				if msg, _ := recovered.(string); !strings.Contains(msg, tt.wantPanic) {
+					t.Fatalf("panic = %v, want it to contain %q", recovered, tt.wantPanic)
+				}
+			}()
+			l := NewRateLimiter(tt.limit, tt.per, tt.opts...)
+			if _, err := l.Allow("client"); err != nil {
+				t.Fatalf("first request: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package gofastapi
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"reflect"
+)
+
+// RequestIDHeader is the header used to propagate request IDs
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// RequestIDFromContext returns the request ID stored in the context, if any
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// EnableRequestID installs middleware that assigns every request an ID, taken from the
+// incoming X-Request-ID header or generated if absent, and echoes it in the response
+func (r *Router) EnableRequestID() {
+	r.Use(requestIDMiddleware)
+}
+
+// requestIDMiddleware propagates or generates the request ID
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		id := req.Header.Get(RequestIDHeader)
+		if id == "" {
+			id = generateRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		ctx := context.WithValue(req.Context(), requestIDKey{}, id)
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+// generateRequestID returns a random 128-bit hex encoded ID
+func generateRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// RequestIDExtractor extracts the request ID from the request context
+type RequestIDExtractor struct {
+	fieldType reflect.Type
+}
+
+func (e *RequestIDExtractor) Extract(r *http.Request, vars map[string]string, body []byte) (interface{}, error) {
+	return RequestIDFromContext(r.Context()), nil
+}
+
+// requestAttrs returns the log attributes identifying r followed by attrs, so
+// framework log records share the method, path and request_id keys
+func requestAttrs(r *http.Request, attrs ...any) []any {
+	return append([]any{"method", r.Method, "path", r.URL.Path, "request_id", RequestIDFromContext(r.Context())}, attrs...)
+}
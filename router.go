@@ -1,10 +1,18 @@
 package gofastapi
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/MarceloPetrucio/go-scalar-api-reference"
 	"github.com/go-playground/validator/v10"
@@ -17,8 +25,19 @@ type Router struct {
 	routes         map[string]*CompiledHandler
 	routeMetadata  map[string]*routeInfo
 	depResolver    *DependencyResolver
+	config         *atomic.Pointer[routerConfig] // Shared with the resolver and compiled handlers
+	tags           tagMapping                    // Struct tags binding fields to request sources
 	errorHandler   ErrorHandler
+	customErrors   bool // Set once SetErrorHandler replaces the default error handler
+	errorMappings  *errorMappings
 	middleware     []mux.MiddlewareFunc
+	strictBody     bool
+	allowOverride  bool        // Let a route registration replace an earlier one
+	notReady       atomic.Bool // Set with SetReady(false) to fail readiness checks
+	requiredDeps   []string
+	metrics        MetricsObserver
+	hooks          *lifecycleHooks
+	sseStreams     *sseRegistry
 	openAPIBuilder *OpenAPIBuilder
 	mu             sync.RWMutex
 	openapiJSONURL *string
@@ -29,16 +48,61 @@ type routeInfo struct {
 	path         string
 	handler      *CompiledHandler
 	dependencies []string
+	reqType      reflect.Type
+	respType     reflect.Type
+	sse          bool
+	hidden       bool
+}
+
+// routerConfig holds the router-level settings read while serving requests. A
+// published config is never modified; the router stores an updated copy instead.
+type routerConfig struct {
+	validationStatus int  // HTTP status of request validation failures
+	lenientArrays    bool // Bind scalar JSON values to slice fields
+	maxBodySize      int64
+	responseEnvelope ResponseEnvelope
+	pageParam        string // Query parameter rewritten in Page Link headers
+	logger           *slog.Logger
+}
+
+// defaultRouterConfig returns a config holder with the router defaults
+func defaultRouterConfig() *atomic.Pointer[routerConfig] {
+	config := &atomic.Pointer[routerConfig]{}
+	config.Store(&routerConfig{validationStatus: http.StatusBadRequest, pageParam: "page"})
+	return config
+}
+
+// log returns the logger for framework internals
+func (c *routerConfig) log() *slog.Logger {
+	if c.logger == nil {
+		return slog.Default()
+	}
+	return c.logger
+}
+
+// updateConfig applies update to a copy of the router's config and publishes it
+func (r *Router) updateConfig(update func(*routerConfig)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	config := *r.config.Load()
+	update(&config)
+	r.config.Store(&config)
 }
 
 // New creates a new router instance
 func New() *Router {
+	mappings := &errorMappings{}
+	depResolver := NewDependencyResolver()
 	return &Router{
 		mux:            mux.NewRouter(),
 		routes:         make(map[string]*CompiledHandler),
 		routeMetadata:  make(map[string]*routeInfo),
-		depResolver:    NewDependencyResolver(),
-		errorHandler:   defaultErrorHandler,
+		depResolver:    depResolver,
+		config:         depResolver.config,
+		errorHandler:   newDefaultErrorHandler(mappings, nil, depResolver.config),
+		errorMappings:  mappings,
+		hooks:          &lifecycleHooks{},
+		sseStreams:     newSSERegistry(),
 		openAPIBuilder: NewOpenAPIBuilder("API", "1.0.0"),
 	}
 }
@@ -53,19 +117,30 @@ func NewWithOpenAPI(title, version, description string) *Router {
 	return r
 }
 
-// RegisterDependency registers a dependency for injection
-func (r *Router) RegisterDependency(name string, dep interface{}, schemeTypes ...SecuritySchemeType) error {
-	err := r.depResolver.Register(name, dep)
+// SecuredDependency can be implemented by dependencies to declare the security schemes
+// they authenticate with, used when RegisterDependency is given none
+type SecuredDependency interface {
+	SecuritySchemes() []SecuritySchemeType
+}
+
+// RegisterDependency registers a dependency for injection. The security scheme types
+// among opts document how the dependency authenticates: several types on one
+// dependency are alternatives (any one suffices), while routes using several
+// dependencies require the schemes of all of them. Other options set a timeout or
+// circuit breaker.
+func (r *Router) RegisterDependency(name string, dep interface{}, opts ...DependencyOption) error {
+	cfg, err := newDependencyConfig(opts)
 	if err != nil {
+		return fmt.Errorf("dependency %s: %w", name, err)
+	}
+	if err := r.depResolver.register(name, dep, cfg, r.tagMapping()); err != nil {
 		return err
 	}
-	for _, schemeType := range schemeTypes {
-		err := r.openAPIBuilder.AddSecurityScheme(schemeType)
-		if err != nil {
-			return err
-		}
+	schemeTypes := cfg.schemeTypes
+	if secured, ok := dep.(SecuredDependency); ok && len(schemeTypes) == 0 {
+		schemeTypes = secured.SecuritySchemes()
 	}
-	return nil
+	return r.openAPIBuilder.SetDependencySchemes(name, schemeTypes...)
 }
 
 // RegisterValidationRule adds a new validation rule to the underlying validator.
@@ -73,19 +148,425 @@ func (r *Router) RegisterValidationRule(tag string, fn validator.Func) error {
 	return addValidationRule(tag, fn)
 }
 
-// SetErrorHandler sets a custom error handler
-func (r *Router) SetErrorHandler(handler ErrorHandler) {
+// SetNamingPolicy sets how untagged struct fields are named in generated schemas.
+// Call it before registering routes.
+func (r *Router) SetNamingPolicy(policy NamingPolicy) {
+	r.openAPIBuilder.SetNamingPolicy(policy)
+}
+
+// ReuseParameters moves parameters that appear identically in at least minOccurrences
+// operations (e.g. page/page_size) into components.parameters, referenced via $ref
+func (r *Router) ReuseParameters(minOccurrences int) {
+	r.openAPIBuilder.SetParameterReuse(minOccurrences)
+}
+
+// SetValidationErrorStatus sets the HTTP status returned when request validation fails
+func (r *Router) SetValidationErrorStatus(status int) {
+	r.updateConfig(func(c *routerConfig) { c.validationStatus = status })
+	r.openAPIBuilder.SetValidationErrorStatus(status)
+}
+
+// EnableLenientArrays accepts a single JSON value where the request expects an array,
+// binding e.g. "tags": "golang" as ["golang"]. Off by default.
+func (r *Router) EnableLenientArrays() {
+	r.updateConfig(func(c *routerConfig) { c.lenientArrays = true })
+}
+
+// SetMaxBodySize rejects JSON request bodies larger than size bytes with 413. The limit
+// applies after gzip or deflate decompression; without it, only decompressed bodies
+// are limited, to 10MB.
+func (r *Router) SetMaxBodySize(size int64) {
+	r.updateConfig(func(c *routerConfig) { c.maxBodySize = size })
+}
+
+// SetLogger sets the logger for framework internals, e.g. internal server errors,
+// response encoding failures and SSE write errors. Defaults to slog.Default().
+func (r *Router) SetLogger(logger *slog.Logger) {
+	r.updateConfig(func(c *routerConfig) { c.logger = logger })
+}
+
+// SetPageParam sets the query parameter holding the page number, which the Link
+// headers of Page responses rewrite. Defaults to "page".
+func (r *Router) SetPageParam(name string) {
+	r.updateConfig(func(c *routerConfig) { c.pageParam = name })
+}
+
+// AddOperationFilter adds a hook that post-processes every generated OpenAPI
+// operation, e.g. to add a common header parameter or vendor extension:
+//
+//	r.AddOperationFilter(func(method, path string, op *gofastapi.Operation) {
+//	    op.Parameters = append(op.Parameters, gofastapi.Parameter{
+//	        Name: "X-Tenant-ID", In: "header", Schema: &gofastapi.Schema{Type: "string"},
+//	    })
+//	})
+//
+// Filters run whenever the spec is generated, on a copy of every operation in its
+// final form (sorted by path and method), before ReuseParameters hoists parameters.
+// Filters run in the order they were added.
+func (r *Router) AddOperationFilter(filter OperationFilter) {
+	r.openAPIBuilder.AddOperationFilter(filter)
+}
+
+// SetStrictBody sets whether routes registered afterwards reject unknown request body fields
+func (r *Router) SetStrictBody(strict bool) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	r.errorHandler = handler
+	r.strictBody = strict
+}
+
+// AllowRouteOverride lets registering a method and path again replace the earlier
+// route, e.g. for hot reloading. By default it is an error.
+func (r *Router) AllowRouteOverride() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.allowOverride = true
+}
+
+// RegisterErrorMapping makes the default error handler respond with status and code
+// to errors matching target via errors.Is, so handlers can return domain errors:
+//
+//	r.RegisterErrorMapping(store.ErrNotFound, http.StatusNotFound, "NOT_FOUND")
+//
+// The message is target's, so wrapping context added on the way up is not exposed.
+// Mappings are checked in registration order and do not apply to *Error or
+// *ValidationError values, or to custom error handlers.
+func (r *Router) RegisterErrorMapping(target error, status int, code string) {
+	r.errorMappings.add(errorMapping{target: target, status: status, code: code})
+}
+
+// RequireDependencies makes routes registered afterwards resolve the given dependencies
+// before their handler runs, whether or not their request struct references them. A
+// failing dependency aborts the request and its security schemes are documented on
+// every route. Exempt individual routes with SkipDependency.
+func (r *Router) RequireDependencies(deps ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requiredDeps = append(r.requiredDeps, deps...)
 }
 
-// Use adds middleware to the router
+// SetErrorHandler sets a custom error handler, which takes precedence over any error
+// envelope. ProblemDetailsErrorHandler also applies the router's error mappings and
+// documents errors as problem+json.
+func (r *Router) SetErrorHandler(handler ErrorHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.customErrors = true
+	r.openAPIBuilder.setErrorEnvelope(nil)
+	problemDetails := isProblemDetailsHandler(handler)
+	if problemDetails {
+		handler = newProblemDetailsErrorHandler(r.errorMappings, r.config)
+	}
+	r.errorHandler = handler
+	r.openAPIBuilder.SetProblemDetails(problemDetails)
+}
+
+// SetErrorEnvelope wraps error responses produced by the default error handler in
+// an E, whose ErrorResponse fields are documented with the error schemas, e.g.
+//
+//	type ErrorBody struct {
+//	    Error gofastapi.ErrorResponse `json:"error"`
+//	}
+//
+//	gofastapi.SetErrorEnvelope(r, func(resp gofastapi.ErrorResponse) ErrorBody {
+//	    return ErrorBody{Error: resp}
+//	})
+//
+// A custom error handler set with SetErrorHandler writes its own responses, so it is
+// kept and the envelope ignored.
+func SetErrorEnvelope[E any](r *Router, envelope func(ErrorResponse) E) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.customErrors {
+		return
+	}
+	wrap := func(resp ErrorResponse) interface{} { return envelope(resp) }
+	r.errorHandler = newDefaultErrorHandler(r.errorMappings, wrap, r.config)
+	r.openAPIBuilder.setErrorEnvelope(reflect.TypeOf((*E)(nil)).Elem())
+	r.openAPIBuilder.SetProblemDetails(false)
+}
+
+// SetResponseEnvelope wraps the JSON responses of successful handlers in an E. The
+// field of E tagged envelope:"response" is documented with the handler's response
+// schema, e.g.
+//
+//	type DataBody struct {
+//	    Data interface{} `json:"data" envelope:"response"`
+//	}
+//
+//	gofastapi.SetResponseEnvelope(r, func(resp interface{}) DataBody {
+//	    return DataBody{Data: resp}
+//	})
+//
+// No-content, file and stream responses and SSE events are sent as is.
+func SetResponseEnvelope[E any](r *Router, envelope func(resp interface{}) E) {
+	wrap := func(resp interface{}) interface{} { return envelope(resp) }
+	r.updateConfig(func(c *routerConfig) { c.responseEnvelope = wrap })
+	r.openAPIBuilder.setResponseEnvelope(wrap, reflect.TypeOf((*E)(nil)).Elem())
+}
+
+// Use adds middleware to the router.
+// Middleware runs in a fixed order: global (outermost), then group, then per-route.
 func (r *Router) Use(middleware ...mux.MiddlewareFunc) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.middleware = append(r.middleware, middleware...)
-	r.mux.Use(middleware...)
+}
+
+// RouteOption configures a single route at registration time
+type RouteOption func(*routeConfig)
+
+type routeConfig struct {
+	middleware          []mux.MiddlewareFunc
+	autoEventID         bool
+	strictBody          bool
+	timeout             time.Duration
+	requestContentType  string
+	responseContentType string
+	anyOf               [][]string
+	skipDeps            map[string]bool
+	skipRequired        bool // Skip every dependency required by the router or group
+	summary             string
+	tags                []string
+	extensions          map[string]interface{}
+	responseVariants    []reflect.Type
+	requestExamples     map[string]*Example
+	responseDescription string
+	responseExample     interface{}
+	parameterExamples   map[string]map[string]*Example // parameter name -> example name -> example
+	interceptors        []Interceptor
+	hidden              bool
+}
+
+// WithMiddleware adds middleware that only applies to the route being registered
+func WithMiddleware(middleware ...mux.MiddlewareFunc) RouteOption {
+	return func(cfg *routeConfig) {
+		cfg.middleware = append(cfg.middleware, middleware...)
+	}
+}
+
+// WithStrictBody rejects request bodies containing fields not declared on the request struct
+func WithStrictBody() RouteOption {
+	return func(cfg *routeConfig) {
+		cfg.strictBody = true
+	}
+}
+
+// WithTimeout bounds request handling with a context deadline, which dependencies and
+// the handler receive and are expected to honor; they are called synchronously, not
+// abandoned. A handler returning after the deadline still fails the request with 504.
+// For SSE routes the timeout covers dependency resolution only.
+func WithTimeout(d time.Duration) RouteOption {
+	return func(c *routeConfig) {
+		c.timeout = d
+	}
+}
+
+// RequireAnyOf requires any one of the given dependencies to succeed, e.g. a bearer
+// token or an API key. They are tried in order until one succeeds; fields referencing
+// the others keep their zero value. If all fail, the first one's error is returned.
+// The OpenAPI security requirements list their schemes as alternatives.
+func RequireAnyOf(deps ...string) RouteOption {
+	return func(c *routeConfig) {
+		c.anyOf = append(c.anyOf, deps)
+	}
+}
+
+// SkipDependency exempts the route from dependencies required by the router or its
+// group with RequireDependencies, e.g. to keep a health check public
+func SkipDependency(deps ...string) RouteOption {
+	return func(c *routeConfig) {
+		if c.skipDeps == nil {
+			c.skipDeps = make(map[string]bool)
+		}
+		for _, dep := range deps {
+			c.skipDeps[dep] = true
+		}
+	}
+}
+
+// WithRequestContentType documents the route's JSON request body under a custom media
+// type, e.g. application/vnd.myapi.v2+json, and rejects bodies sent with any other
+// Content-Type with 415. The body is still parsed as JSON.
+func WithRequestContentType(contentType string) RouteOption {
+	return func(c *routeConfig) {
+		c.requestContentType = contentType
+	}
+}
+
+// WithResponseContentType sends JSON success responses with a custom media type, e.g.
+// "application/vnd.myapi.v2+json", instead of application/json. The body is still
+// encoded as JSON, and the documented success response uses the media type.
+func WithResponseContentType(contentType string) RouteOption {
+	return func(c *routeConfig) {
+		c.responseContentType = contentType
+	}
+}
+
+// WithHidden serves the route but leaves it out of the OpenAPI spec, e.g. for
+// internal endpoints
+func WithHidden() RouteOption {
+	return func(c *routeConfig) {
+		c.hidden = true
+	}
+}
+
+// WithSummary sets the route's operation summary
+func WithSummary(summary string) RouteOption {
+	return func(c *routeConfig) {
+		c.summary = summary
+	}
+}
+
+// WithTags groups the route's operation under the given tags
+func WithTags(tags ...string) RouteOption {
+	return func(c *routeConfig) {
+		c.tags = append(c.tags, tags...)
+	}
+}
+
+// WithExtension adds a vendor extension to the route's operation, e.g. x-internal.
+// The "x-" prefix is added if missing.
+func WithExtension(key string, value interface{}) RouteOption {
+	return func(c *routeConfig) {
+		if !strings.HasPrefix(key, "x-") {
+			key = "x-" + key
+		}
+		if c.extensions == nil {
+			c.extensions = make(map[string]interface{})
+		}
+		c.extensions[key] = value
+	}
+}
+
+// WithAutoEventID assigns monotonically increasing IDs to SSE events that leave ID empty.
+// Numbering resumes after the client's Last-Event-ID header when it is numeric.
+func WithAutoEventID() RouteOption {
+	return func(cfg *routeConfig) {
+		cfg.autoEventID = true
+	}
+}
+
+// WithResponseVariants declares the concrete types a handler with an interface
+// return type may respond with, documented as a oneOf schema
+func WithResponseVariants(variants ...interface{}) RouteOption {
+	return func(cfg *routeConfig) {
+		for _, variant := range variants {
+			cfg.responseVariants = append(cfg.responseVariants, reflect.TypeOf(variant))
+		}
+	}
+}
+
+// WithRequestExample adds a named example request body to the route's documentation
+func WithRequestExample(name string, value interface{}) RouteOption {
+	return func(cfg *routeConfig) {
+		if cfg.requestExamples == nil {
+			cfg.requestExamples = make(map[string]*Example)
+		}
+		cfg.requestExamples[name] = &Example{Summary: name, Value: value}
+	}
+}
+
+// WithResponseDescription sets the description of the route's success response,
+// "Successful response" by default
+func WithResponseDescription(description string) RouteOption {
+	return func(cfg *routeConfig) {
+		cfg.responseDescription = description
+	}
+}
+
+// WithResponseExample sets an example of the route's JSON success response, shown by
+// docs UIs instead of one derived from the schema
+func WithResponseExample(example interface{}) RouteOption {
+	return func(cfg *routeConfig) {
+		cfg.responseExample = example
+	}
+}
+
+// WithParameterExample adds a named example for a path, query or header parameter
+func WithParameterExample(param, name string, value interface{}) RouteOption {
+	return func(cfg *routeConfig) {
+		if cfg.parameterExamples == nil {
+			cfg.parameterExamples = make(map[string]map[string]*Example)
+		}
+		if cfg.parameterExamples[param] == nil {
+			cfg.parameterExamples[param] = make(map[string]*Example)
+		}
+		cfg.parameterExamples[param][name] = &Example{Summary: name, Value: value}
+	}
+}
+
+// checkResponseVariants verifies declared variants implement the handler's interface return type
+func checkResponseVariants(respType reflect.Type, variants []reflect.Type) error {
+	if len(variants) == 0 {
+		return nil
+	}
+	if respType.Kind() != reflect.Interface {
+		return fmt.Errorf("response type %v is not an interface", respType)
+	}
+	for _, variant := range variants {
+		if variant == nil || !variant.Implements(respType) {
+			return fmt.Errorf("%v does not implement %v", variant, respType)
+		}
+	}
+	return nil
+}
+
+func newRouteConfig(opts []RouteOption) *routeConfig {
+	cfg := &routeConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// addRequiredDependencies adds the dependencies required by the router and group to a
+// route as single-member RequireAnyOf groups, resolved before the route's own groups.
+// direct lists the dependencies the handler's request struct references.
+func (r *Router) addRequiredDependencies(cfg *routeConfig, group *SubRouter, direct map[int]string) error {
+	required := r.requiredDeps
+	if group != nil {
+		required = append(slices.Clip(required), group.requiredDeps...)
+	}
+	if cfg.skipRequired {
+		required = nil
+	}
+
+	var groups [][]string
+	seen := make(map[string]bool)
+	for _, dep := range required {
+		if !cfg.skipDeps[dep] && !seen[dep] {
+			seen[dep] = true
+			groups = append(groups, []string{dep})
+		}
+	}
+	for _, dep := range direct {
+		if cfg.skipDeps[dep] {
+			return fmt.Errorf("cannot skip dependency %q referenced by the request struct", dep)
+		}
+	}
+	cfg.anyOf = append(groups, cfg.anyOf...)
+	return nil
+}
+
+// withMiddleware wraps a handler with global, group and route middleware.
+// The chain is built per request so middleware added after registration still applies.
+func (r *Router) withMiddleware(handler http.Handler, group *SubRouter, routeMiddleware []mux.MiddlewareFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.mu.RLock()
+		chain := make([]mux.MiddlewareFunc, 0, len(r.middleware)+len(routeMiddleware))
+		chain = append(chain, r.middleware...)
+		if group != nil {
+			chain = append(chain, group.middleware...)
+		}
+		chain = append(chain, routeMiddleware...)
+		r.mu.RUnlock()
+
+		h := handler
+		for i := len(chain) - 1; i >= 0; i-- {
+			h = chain[i](h)
+		}
+		h.ServeHTTP(w, req)
+	})
 }
 
 // ServeHTTP implements http.Handler
@@ -94,66 +575,101 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 }
 
 // GET registers a GET route
-func (r *Router) GET(path string, handler interface{}) error {
-	return r.registerRoute(http.MethodGet, path, handler)
+func (r *Router) GET(path string, handler interface{}, opts ...RouteOption) error {
+	return r.registerRoute(http.MethodGet, path, handler, nil, opts)
 }
 
 // POST registers a POST route
-func (r *Router) POST(path string, handler interface{}) error {
-	return r.registerRoute(http.MethodPost, path, handler)
+func (r *Router) POST(path string, handler interface{}, opts ...RouteOption) error {
+	return r.registerRoute(http.MethodPost, path, handler, nil, opts)
 }
 
 // PUT registers a PUT route
-func (r *Router) PUT(path string, handler interface{}) error {
-	return r.registerRoute(http.MethodPut, path, handler)
+func (r *Router) PUT(path string, handler interface{}, opts ...RouteOption) error {
+	return r.registerRoute(http.MethodPut, path, handler, nil, opts)
 }
 
 // PATCH registers a PATCH route
-func (r *Router) PATCH(path string, handler interface{}) error {
-	return r.registerRoute(http.MethodPatch, path, handler)
+func (r *Router) PATCH(path string, handler interface{}, opts ...RouteOption) error {
+	return r.registerRoute(http.MethodPatch, path, handler, nil, opts)
 }
 
 // DELETE registers a DELETE route
-func (r *Router) DELETE(path string, handler interface{}) error {
-	return r.registerRoute(http.MethodDelete, path, handler)
+func (r *Router) DELETE(path string, handler interface{}, opts ...RouteOption) error {
+	return r.registerRoute(http.MethodDelete, path, handler, nil, opts)
 }
 
 // registerRoute compiles and registers a route handler
-func (r *Router) registerRoute(method, path string, handler interface{}) error {
+func (r *Router) registerRoute(method, path string, handler interface{}, group *SubRouter, opts []RouteOption) error {
+	cfg := newRouteConfig(opts)
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	cfg.strictBody = cfg.strictBody || r.strictBody
+
+	routeKey := fmt.Sprintf("%s:%s", method, path)
+	_, exists := r.routeMetadata[routeKey]
+	if exists && !r.allowOverride {
+		return fmt.Errorf("route %s %s is already registered", method, path)
+	}
 
 	// Compile the handler
-	compiled, err := compileHandler(handler)
+	compiled, err := compileHandler(handler, r.tags)
 	if err != nil {
 		return fmt.Errorf("failed to compile handler for %s %s: %w", method, path, err)
 	}
+	if err := checkBodylessFields(method, compiled.reqType, compiled.extractors); err != nil {
+		return fmt.Errorf("failed to compile handler for %s %s: %w", method, path, err)
+	}
+	if err := r.depResolver.checkAnyOf(cfg.anyOf); err != nil {
+		return fmt.Errorf("invalid dependencies for %s %s: %w", method, path, err)
+	}
+	if err := r.addRequiredDependencies(cfg, group, compiled.dependencies); err != nil {
+		return fmt.Errorf("invalid dependencies for %s %s: %w", method, path, err)
+	}
+	compiled.strictBody = cfg.strictBody
+	compiled.timeout = cfg.timeout
+	compiled.contentType = cfg.requestContentType
+	compiled.responseContentType = cfg.responseContentType
+	compiled.hooks = r.hooks
+	compiled.interceptors = cfg.interceptors
+	compiled.config = r.config
+	compiled.anyOf = cfg.anyOf
+	if err := checkResponseVariants(compiled.respType, cfg.responseVariants); err != nil {
+		return fmt.Errorf("invalid response variants for %s %s: %w", method, path, err)
+	}
 
-	// Extract dependencies from the handler
-	var dependencies []string
-	for _, depName := range compiled.dependencies {
-		dependencies = append(dependencies, depName)
+	// Collect dependencies, including those the handler's dependencies rely on
+	dependencies := r.depResolver.dependencyClosure(compiled.dependencies, cfg.anyOf)
+	if err := checkPathParams(path, compiled.reqType, compiled.extractors, r.depResolver.pathParams(dependencies)); err != nil {
+		return fmt.Errorf("failed to compile handler for %s %s: %w", method, path, err)
 	}
 
 	// Store compiled handler and metadata
-	routeKey := fmt.Sprintf("%s:%s", method, path)
 	r.routes[routeKey] = compiled
 	r.routeMetadata[routeKey] = &routeInfo{
 		method:       method,
 		path:         path,
 		handler:      compiled,
 		dependencies: dependencies,
+		reqType:      compiled.reqType,
+		respType:     compiled.respType,
+		hidden:       cfg.hidden,
 	}
 
-	// Add to OpenAPI spec
-	r.openAPIBuilder.AddRoute(method, path, compiled, dependencies)
+	// Add to OpenAPI spec, removing the operation of a visible route this one replaces
+	if !cfg.hidden {
+		r.openAPIBuilder.addRoute(method, path, compiled, dependencies, cfg)
+	} else if exists {
+		r.openAPIBuilder.removeRoute(method, path)
+	}
 
 	// Register with mux
-	r.mux.HandleFunc(path, func(w http.ResponseWriter, req *http.Request) {
+	routeHandler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		// Get the compiled handler
 		r.mu.RLock()
 		handler := r.routes[routeKey]
-		errorHandler := r.errorHandler
+		errorHandler := withFieldErrorStatus(r.errorHandler, r.config.Load().validationStatus)
 		r.mu.RUnlock()
 
 		if handler == nil {
@@ -164,7 +680,13 @@ func (r *Router) registerRoute(method, path string, handler interface{}) error {
 		// Execute the compiled handler with the error handler
 		ctx := req.Context()
 		handler.Execute(ctx, w, req, r.depResolver, errorHandler)
-	}).Methods(method)
+	})
+	wrapped := r.withMetrics(method, path, r.withMiddleware(routeHandler, group, cfg.middleware))
+	if exists {
+		r.mux.Get(routeKey).Handler(wrapped)
+	} else {
+		r.mux.Handle(path, wrapped).Methods(method).Name(routeKey)
+	}
 
 	return nil
 }
@@ -174,113 +696,231 @@ func (r *Router) Group(prefix string) *SubRouter {
 	return &SubRouter{
 		router: r,
 		prefix: prefix,
-		mux:    r.mux.PathPrefix(prefix).Subrouter(),
 	}
 }
 
+// Mount serves h for every request whose path starts with prefix, e.g. a metrics
+// endpoint or pprof mux. The request path is passed through unchanged; wrap h in
+// http.StripPrefix if it expects paths relative to prefix. Global middleware applies,
+// but mounted handlers are not compiled and do not appear in the OpenAPI spec.
+func (r *Router) Mount(prefix string, h http.Handler) {
+	r.mux.PathPrefix(prefix).Handler(r.withMiddleware(h, nil, nil))
+}
+
 // SSEGET registers an SSE GET route
-func (r *Router) SSEGET(path string, handler interface{}) error {
-	return r.registerSSERoute(http.MethodGet, path, handler)
+func (r *Router) SSEGET(path string, handler interface{}, opts ...RouteOption) error {
+	return r.registerSSERoute(http.MethodGet, path, handler, nil, opts)
 }
 
 // SSEPOST registers an SSE POST route
-func (r *Router) SSEPOST(path string, handler interface{}) error {
-	return r.registerSSERoute(http.MethodPost, path, handler)
+func (r *Router) SSEPOST(path string, handler interface{}, opts ...RouteOption) error {
+	return r.registerSSERoute(http.MethodPost, path, handler, nil, opts)
 }
 
 // registerSSERoute compiles and registers an SSE route handler
-func (r *Router) registerSSERoute(method, path string, handler interface{}) error {
+func (r *Router) registerSSERoute(method, path string, handler interface{}, group *SubRouter, opts []RouteOption) error {
+	cfg := newRouteConfig(opts)
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	cfg.strictBody = cfg.strictBody || r.strictBody
+
+	routeKey := fmt.Sprintf("%s:%s", method, path)
+	_, exists := r.routeMetadata[routeKey]
+	if exists && !r.allowOverride {
+		return fmt.Errorf("route %s %s is already registered", method, path)
+	}
 
 	// Compile the SSE handler
-	compiled, err := compileSSEHandler(handler)
+	compiled, err := compileSSEHandler(handler, r.tags)
 	if err != nil {
 		return fmt.Errorf("failed to compile SSE handler for %s %s: %w", method, path, err)
 	}
-
-	// Extract dependencies
-	var dependencies []string
-	for _, depName := range compiled.dependencies {
-		dependencies = append(dependencies, depName)
+	if err := checkBodylessFields(method, compiled.reqType, compiled.extractors); err != nil {
+		return fmt.Errorf("failed to compile SSE handler for %s %s: %w", method, path, err)
+	}
+	if err := r.depResolver.checkAnyOf(cfg.anyOf); err != nil {
+		return fmt.Errorf("invalid dependencies for %s %s: %w", method, path, err)
+	}
+	if err := r.addRequiredDependencies(cfg, group, compiled.dependencies); err != nil {
+		return fmt.Errorf("invalid dependencies for %s %s: %w", method, path, err)
+	}
+	compiled.autoEventID = cfg.autoEventID
+	compiled.strictBody = cfg.strictBody
+	compiled.timeout = cfg.timeout
+	compiled.contentType = cfg.requestContentType
+	compiled.hooks = r.hooks
+	compiled.anyOf = cfg.anyOf
+	compiled.streams = r.sseStreams
+	compiled.config = r.config
+
+	// Collect dependencies, including those the handler's dependencies rely on
+	dependencies := r.depResolver.dependencyClosure(compiled.dependencies, cfg.anyOf)
+	if err := checkPathParams(path, compiled.reqType, compiled.extractors, r.depResolver.pathParams(dependencies)); err != nil {
+		return fmt.Errorf("failed to compile SSE handler for %s %s: %w", method, path, err)
 	}
 
 	// Store metadata (reuse existing routeInfo structure)
-	routeKey := fmt.Sprintf("%s:%s", method, path)
+	delete(r.routes, routeKey) // In case it overrides a regular route
 	r.routeMetadata[routeKey] = &routeInfo{
 		method:       method,
 		path:         path,
 		handler:      nil, // SSE handlers don't use regular CompiledHandler
 		dependencies: dependencies,
+		reqType:      compiled.reqType,
+		respType:     compiled.respType,
+		sse:          true,
+		hidden:       cfg.hidden,
 	}
 
-	// Add to OpenAPI spec
-	r.openAPIBuilder.AddSSERoute(method, path, compiled, dependencies)
+	// Add to OpenAPI spec, removing the operation of a visible route this one replaces
+	if !cfg.hidden {
+		r.openAPIBuilder.addSSERoute(method, path, compiled, dependencies, cfg)
+	} else if exists {
+		r.openAPIBuilder.removeRoute(method, path)
+	}
 
 	// Register with mux
-	r.mux.HandleFunc(path, func(w http.ResponseWriter, req *http.Request) {
+	routeHandler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		r.mu.RLock()
-		errorHandler := r.errorHandler
+		errorHandler := withFieldErrorStatus(r.errorHandler, r.config.Load().validationStatus)
 		r.mu.RUnlock()
 
 		// Execute the compiled SSE handler
 		ctx := req.Context()
 		compiled.Execute(ctx, w, req, r.depResolver, errorHandler)
-	}).Methods(method)
+	})
+	wrapped := r.withMetrics(method, path, r.withMiddleware(routeHandler, group, cfg.middleware))
+	if exists {
+		r.mux.Get(routeKey).Handler(wrapped)
+	} else {
+		r.mux.Handle(path, wrapped).Methods(method).Name(routeKey)
+	}
 
 	return nil
 }
 
+// RouteType describes the Go types of a registered route, e.g. for client code
+// generators that inspect types directly rather than through the OpenAPI spec
+type RouteType struct {
+	Method string
+	Path   string
+	Req    reflect.Type // The handler's request struct
+	Resp   reflect.Type // The response; the event data type T for SSE routes
+	SSE    bool
+	Hidden bool // Left out of the OpenAPI spec with WithHidden
+}
+
+// RouteTypes returns the request and response types of all registered routes, sorted
+// by path and method
+func (r *Router) RouteTypes() []RouteType {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := make([]RouteType, 0, len(r.routeMetadata))
+	for _, info := range r.routeMetadata {
+		types = append(types, RouteType{
+			Method: info.method,
+			Path:   info.path,
+			Req:    info.reqType,
+			Resp:   info.respType,
+			SSE:    info.sse,
+			Hidden: info.hidden,
+		})
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if types[i].Path != types[j].Path {
+			return types[i].Path < types[j].Path
+		}
+		return types[i].Method < types[j].Method
+	})
+	return types
+}
+
+// ActiveSSEStreams returns the number of SSE streams currently open
+func (r *Router) ActiveSSEStreams() int {
+	return r.sseStreams.count()
+}
+
+// CloseAllSSE cancels the context of every open SSE stream, ending them. Handlers'
+// iterators see ctx.Done() and no further events are written. http.Server.Shutdown
+// waits for open streams, so register it to drain them:
+//
+//	server.RegisterOnShutdown(r.CloseAllSSE)
+func (r *Router) CloseAllSSE() {
+	r.sseStreams.closeAll()
+}
+
 // SubRouter represents a group of routes with a common prefix
 type SubRouter struct {
-	router *Router
-	prefix string
-	mux    *mux.Router
+	router       *Router
+	prefix       string
+	middleware   []mux.MiddlewareFunc
+	requiredDeps []string
 }
 
 // GET registers a GET route in the group
-func (sr *SubRouter) GET(path string, handler interface{}) error {
+func (sr *SubRouter) GET(path string, handler interface{}, opts ...RouteOption) error {
 	fullPath := sr.prefix + path
-	return sr.router.registerRoute(http.MethodGet, fullPath, handler)
+	return sr.router.registerRoute(http.MethodGet, fullPath, handler, sr, opts)
 }
 
 // POST registers a POST route in the group
-func (sr *SubRouter) POST(path string, handler interface{}) error {
+func (sr *SubRouter) POST(path string, handler interface{}, opts ...RouteOption) error {
 	fullPath := sr.prefix + path
-	return sr.router.registerRoute(http.MethodPost, fullPath, handler)
+	return sr.router.registerRoute(http.MethodPost, fullPath, handler, sr, opts)
 }
 
 // PUT registers a PUT route in the group
-func (sr *SubRouter) PUT(path string, handler interface{}) error {
+func (sr *SubRouter) PUT(path string, handler interface{}, opts ...RouteOption) error {
 	fullPath := sr.prefix + path
-	return sr.router.registerRoute(http.MethodPut, fullPath, handler)
+	return sr.router.registerRoute(http.MethodPut, fullPath, handler, sr, opts)
 }
 
 // PATCH registers a PATCH route in the group
-func (sr *SubRouter) PATCH(path string, handler interface{}) error {
+func (sr *SubRouter) PATCH(path string, handler interface{}, opts ...RouteOption) error {
 	fullPath := sr.prefix + path
-	return sr.router.registerRoute(http.MethodPatch, fullPath, handler)
+	return sr.router.registerRoute(http.MethodPatch, fullPath, handler, sr, opts)
 }
 
 // DELETE registers a DELETE route in the group
-func (sr *SubRouter) DELETE(path string, handler interface{}) error {
+func (sr *SubRouter) DELETE(path string, handler interface{}, opts ...RouteOption) error {
 	fullPath := sr.prefix + path
-	return sr.router.registerRoute(http.MethodDelete, fullPath, handler)
+	return sr.router.registerRoute(http.MethodDelete, fullPath, handler, sr, opts)
 }
 
-// Use adds middleware to the subrouter
+// Use adds middleware to the subrouter. It runs after global middleware
+// and before any per-route middleware.
 func (sr *SubRouter) Use(middleware ...mux.MiddlewareFunc) {
-	sr.mux.Use(middleware...)
+	sr.router.mu.Lock()
+	defer sr.router.mu.Unlock()
+	sr.middleware = append(sr.middleware, middleware...)
+}
+
+// RequireDependencies makes the group's routes registered afterwards resolve the given
+// dependencies, in addition to those required by the router
+func (sr *SubRouter) RequireDependencies(deps ...string) {
+	sr.router.mu.Lock()
+	defer sr.router.mu.Unlock()
+	sr.requiredDeps = append(sr.requiredDeps, deps...)
 }
 
-func (sr *SubRouter) SSEGET(path string, handler interface{}) error {
+// SSEGET registers an SSE GET route in the group
+func (sr *SubRouter) SSEGET(path string, handler interface{}, opts ...RouteOption) error {
 	fullPath := sr.prefix + path
-	return sr.router.registerSSERoute(http.MethodGet, fullPath, handler)
+	return sr.router.registerSSERoute(http.MethodGet, fullPath, handler, sr, opts)
 }
 
-func (sr *SubRouter) SSEPOST(path string, handler interface{}) error {
+// SSEPOST registers an SSE POST route in the group
+func (sr *SubRouter) SSEPOST(path string, handler interface{}, opts ...RouteOption) error {
 	fullPath := sr.prefix + path
-	return sr.router.registerSSERoute(http.MethodPost, fullPath, handler)
+	return sr.router.registerSSERoute(http.MethodPost, fullPath, handler, sr, opts)
+}
+
+// Mount serves h under the group's prefix joined with prefix. Global and group
+// middleware apply; mounted handlers do not appear in the OpenAPI spec.
+func (sr *SubRouter) Mount(prefix string, h http.Handler) {
+	sr.router.mux.PathPrefix(sr.prefix + prefix).Handler(sr.router.withMiddleware(h, sr, nil))
 }
 
 // GenerateOpenAPISpec returns the OpenAPI specification
@@ -290,18 +930,55 @@ func (r *Router) GenerateOpenAPISpec() *OpenAPISpec {
 	return r.openAPIBuilder.GetSpec()
 }
 
+// JSONSchemaFor generates a standalone JSON Schema (Draft 2020-12) document for t,
+// with referenced types under $defs, e.g. to validate payloads in non-Go services:
+//
+//	schema, err := r.JSONSchemaFor(reflect.TypeOf(CreateUserRequest{}))
+func (r *Router) JSONSchemaFor(t reflect.Type) (*Schema, error) {
+	return r.openAPIBuilder.JSONSchemaFor(t)
+}
+
+// MarshalOpenAPI returns the OpenAPI spec as canonical, indented JSON: object keys
+// are sorted at every level (including extensions and examples) and parameters keep
+// their declaration order, so the output is byte-stable and suitable for committing
+// and diffing in CI.
+func (r *Router) MarshalOpenAPI() ([]byte, error) {
+	data, err := json.Marshal(r.GenerateOpenAPISpec())
+	if err != nil {
+		return nil, err
+	}
+
+	// Round-trip through generic values so custom marshalers' key order is sorted too
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var canonical interface{}
+	if err := decoder.Decode(&canonical); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(canonical); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 // ServeOpenAPIJSON serves the OpenAPI spec as JSON at the specified path
 func (r *Router) ServeOpenAPIJSON(path string) {
-	r.mux.HandleFunc(path, func(w http.ResponseWriter, req *http.Request) {
+	r.mux.Handle(path, r.withMiddleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		spec := r.GenerateOpenAPISpec()
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("Access-Control-Allow-Origin", "*") // For Swagger UI
 		json.NewEncoder(w).Encode(spec)
-	}).Methods(http.MethodGet)
+	}), nil, nil)).Methods(http.MethodGet)
 	r.openapiJSONURL = &path
 }
 
-// ServeDocs serves the OpenAPI spec as HTML doc at the specified path
+// ServeDocs serves the OpenAPI spec as HTML doc at the specified path, rendered with
+// Scalar. Use ServeDocsWith for Swagger UI, Redoc or a custom renderer.
 func (r *Router) ServeDocs(baseURL string, path string, options *scalar.Options) {
 	if r.openapiJSONURL == nil {
 		r.ServeOpenAPIJSON("/openapi.json")
@@ -317,18 +994,21 @@ func (r *Router) ServeDocs(baseURL string, path string, options *scalar.Options)
 			DarkMode: true,
 		}
 	}
-	r.mux.HandleFunc(path, func(w http.ResponseWriter, req *http.Request) {
-		html, err := scalar.ApiReferenceHTML(options)
-		if err != nil {
-			http.Error(w, "Failed to generate API reference HTML", http.StatusInternalServerError)
-			return
-		}
-		w.Header().Set("Content-Type", "text/html")
-		w.Write([]byte(html))
-	}).Methods(http.MethodGet)
+	r.ServeDocsWith(path, ScalarDocs{Options: options})
 }
 
 // AddServer adds a server to the OpenAPI spec
 func (r *Router) AddServer(url, description string) {
 	r.openAPIBuilder.AddServer(url, description)
 }
+
+// AddServerWithVariables adds a server with a templated URL to the OpenAPI spec, e.g.
+// for multi-region deployments:
+//
+//	r.AddServerWithVariables("https://{region}.api.example.com", "Regional API",
+//	    map[string]gofastapi.ServerVariable{
+//	        "region": {Default: "eu", Enum: []string{"eu", "us"}},
+//	    })
+func (r *Router) AddServerWithVariables(url, description string, vars map[string]ServerVariable) error {
+	return r.openAPIBuilder.AddServerWithVariables(url, description, vars)
+}
@@ -0,0 +1,271 @@
+package gofastapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+type itemPathDep struct{}
+
+type itemPathDepRequest struct {
+	ID string `path:"id"`
+}
+
+func (itemPathDep) Handle(ctx context.Context, req itemPathDepRequest) (string, error) {
+	return req.ID, nil
+}
+
+func TestRegisterChecksPathParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler interface{}
+		wantErr string
+	}{
+		{
+			name: "path field",
+			handler: func(ctx context.Context, req struct {
+				ID string `path:"id"`
+			}) (string, error) {
+				return req.ID, nil
+			},
+		},
+		{
+			name: "source fallback",
+			handler: func(ctx context.Context, req struct {
+				ID string `source:"path:id,query:id"`
+			}) (string, error) {
+				return req.ID, nil
+			},
+		},
+		{
+			name: "pathparams map",
+			handler: func(ctx context.Context, req struct {
+				Params map[string]string `pathparams:""`
+			}) (string, error) {
+				return req.Params["id"], nil
+			},
+		},
+		{
+			name: "request field",
+			handler: func(ctx context.Context, req struct {
+				Request *http.Request `request:""`
+			}) (string, error) {
+				return req.Request.URL.Path, nil
+			},
+		},
+		{
+			name: "dependency",
+			handler: func(ctx context.Context, req struct {
+				ID string `dep:"item"`
+			}) (string, error) {
+				return req.ID, nil
+			},
+		},
+		{
+			name: "field without template variable",
+			handler: func(ctx context.Context, req struct {
+				ID   string `path:"id"`
+				Slug string `path:"slug"`
+			}) (string, error) {
+				return req.ID, nil
+			},
+			wantErr: `field Slug reads path parameter "slug", but the route template /items/{id} has no {slug}`,
+		},
+		{
+			name: "template variable without field",
+			handler: func(ctx context.Context, req struct {
+				Q string `query:"q"`
+			}) (string, error) {
+				return req.Q, nil
+			},
+			wantErr: "route template /items/{id} declares {id}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New()
+			if err := r.RegisterDependency("item", itemPathDep{}); err != nil {
+				t.Fatal(err)
+			}
+			err := r.GET("/items/{id}", tt.handler)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("GET /items/{id}: unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("GET /items/{id}: error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	var calls []string
+	record := func(name string) mux.MiddlewareFunc {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				calls = append(calls, name+" before")
+				next.ServeHTTP(w, req)
+				calls = append(calls, name+" after")
+			})
+		}
+	}
+
+	r := New()
+	r.Use(record("global"))
+	api := r.Group("/api")
+	api.Use(record("group"))
+	err := api.GET("/ping", func(ctx context.Context, req struct{}) (string, error) {
+		calls = append(calls, "handler")
+		return "pong", nil
+	}, WithMiddleware(record("route")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Middleware added after registration still applies, in the same position
+	r.Use(record("global2"))
+
+	resp, err := r.TestRequest(http.MethodGet, "/api/ping", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	want := []string{
+		"global before", "global2 before", "group before", "route before",
+		"handler",
+		"route after", "group after", "global2 after", "global after",
+	}
+	if !slices.Equal(calls, want) {
+		t.Fatalf("calls = %v, want %v", calls, want)
+	}
+}
+
+func TestHiddenRoute(t *testing.T) {
+	handler := func(ctx context.Context, req struct{}) (string, error) {
+		return "ok", nil
+	}
+
+	r := New()
+	if err := r.GET("/internal", handler, WithHidden()); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := r.TestRequest(http.MethodGet, "/internal", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("hidden route status = %d, want 200", resp.StatusCode)
+	}
+	if _, ok := r.GenerateOpenAPISpec().Paths["/internal"]; ok {
+		t.Error("hidden route is documented in the spec")
+	}
+
+	// A hidden route replacing a documented one removes its operation
+	r.AllowRouteOverride()
+	if err := r.GET("/status", handler); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.POST("/status", handler); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.GET("/status", handler, WithHidden()); err != nil {
+		t.Fatal(err)
+	}
+	pathItem := r.GenerateOpenAPISpec().Paths["/status"]
+	if pathItem == nil || pathItem.Post == nil {
+		t.Fatal("POST /status is missing from the spec")
+	}
+	if pathItem.Get != nil {
+		t.Error("GET /status is still documented after being replaced by a hidden route")
+	}
+}
+
+func TestDuplicateRoute(t *testing.T) {
+	first := func(ctx context.Context, req struct{}) (string, error) {
+		return "first", nil
+	}
+	second := func(ctx context.Context, req struct{}) (string, error) {
+		return "second", nil
+	}
+
+	r := New()
+	if err := r.GET("/items", first); err != nil {
+		t.Fatal(err)
+	}
+	err := r.GET("/items", second)
+	if err == nil || !strings.Contains(err.Error(), "route GET /items is already registered") {
+		t.Fatalf("duplicate registration error = %v, want already registered", err)
+	}
+
+	r.AllowRouteOverride()
+	if err := r.GET("/items", second); err != nil {
+		t.Fatalf("override after AllowRouteOverride: %v", err)
+	}
+	resp, err := r.TestRequest(http.MethodGet, "/items", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body string
+	if err := resp.DecodeJSON(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body != "second" {
+		t.Errorf("response = %q, want the overriding handler's", body)
+	}
+}
+
+func TestRequireAnyOfChecksDependencies(t *testing.T) {
+	handler := func(ctx context.Context, req struct{}) (string, error) {
+		return "ok", nil
+	}
+
+	r := New()
+	if err := r.RegisterDependency("item", itemPathDep{}); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		opt     RouteOption
+		wantErr string
+	}{
+		{name: "registered", opt: RequireAnyOf("item")},
+		{name: "empty group", opt: RequireAnyOf(), wantErr: "RequireAnyOf needs at least one dependency"},
+		{name: "unregistered", opt: RequireAnyOf("item", "apikey"), wantErr: `RequireAnyOf dependency "apikey" is not registered`},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := fmt.Sprintf("/reports/%d", i)
+			err := r.GET(path, handler, tt.opt)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("GET %s: unexpected error: %v", path, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("GET %s: error = %v, want it to contain %q", path, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTestRequestReportsMalformedRequests(t *testing.T) {
+	r := New()
+	if _, err := r.TestRequest("GET /", "/items", nil); err == nil {
+		t.Error("invalid method: want an error, got none")
+	}
+	if _, err := r.TestRequest(http.MethodGet, "/items\x7f%zz", nil); err == nil {
+		t.Error("invalid path: want an error, got none")
+	}
+}
@@ -3,11 +3,19 @@ package gofastapi
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"net"
 	"net/http"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 )
 
 // EventData represents a Server-Sent Event
@@ -16,6 +24,10 @@ type EventData[T any] struct {
 	Data  T      `json:"data"`            // The actual data payload (required)
 	ID    string `json:"id,omitempty"`    // SSE id field (optional)
 	Retry int    `json:"retry,omitempty"` // SSE retry field in milliseconds (optional)
+
+	// Comment is written as SSE comment lines (": ...") before the event, e.g. for
+	// debug notes that clients ignore (optional)
+	Comment string `json:"-"`
 }
 
 // SSECompiledHandler represents a pre-compiled SSE handler
@@ -25,12 +37,66 @@ type SSECompiledHandler struct {
 	respType     reflect.Type // The T in iter.Seq[EventData[T]]
 	extractors   map[int]FieldExtractor
 	validators   map[int]string
+	fieldSources map[string]string
 	dependencies map[int]string
 	hasJSONBody  bool
+	bodyFields   map[string]reflect.Type
+	strictBody   bool
+	autoEventID  bool          // Fill in missing event IDs from a per-stream counter
+	timeout      time.Duration // Deadline for request preparation; 0 disables
+	contentType  string        // Required JSON body media type; empty accepts any
+	hooks        *lifecycleHooks
+	anyOf        [][]string   // Groups of dependencies of which any one must succeed
+	streams      *sseRegistry // Registry of the router's open streams
+	config       *atomic.Pointer[routerConfig]
+}
+
+// sseRegistry tracks open SSE streams so they can be counted and closed
+type sseRegistry struct {
+	streams map[uint64]context.CancelFunc
+	nextID  uint64
+	mu      sync.Mutex
+}
+
+func newSSERegistry() *sseRegistry {
+	return &sseRegistry{streams: make(map[uint64]context.CancelFunc)}
+}
+
+// add registers a stream's cancel function and returns a func that removes it
+func (reg *sseRegistry) add(cancel context.CancelFunc) func() {
+	if reg == nil {
+		return func() {}
+	}
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	reg.nextID++
+	id := reg.nextID
+	reg.streams[id] = cancel
+	return func() {
+		reg.mu.Lock()
+		defer reg.mu.Unlock()
+		delete(reg.streams, id)
+	}
+}
+
+// count returns the number of open streams
+func (reg *sseRegistry) count() int {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	return len(reg.streams)
+}
+
+// closeAll cancels every open stream
+func (reg *sseRegistry) closeAll() {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	for _, cancel := range reg.streams {
+		cancel()
+	}
 }
 
 // compileSSEHandler pre-compiles an SSE handler function
-func compileSSEHandler(handler interface{}) (*SSECompiledHandler, error) {
+func compileSSEHandler(handler interface{}, tags tagMapping) (*SSECompiledHandler, error) {
 	handlerType := reflect.TypeOf(handler)
 	handlerValue := reflect.ValueOf(handler)
 
@@ -68,13 +134,13 @@ func compileSSEHandler(handler interface{}) (*SSECompiledHandler, error) {
 	reqType := handlerType.In(1)
 
 	// Reuse existing compilation logic
-	extractors, validators, err := compileStructExtractors(reqType)
+	extractors, validators, err := compileStructExtractors(reqType, tags)
 	if err != nil {
 		return nil, err
 	}
 
 	// Check for dependencies and JSON body
-	dependencies, hasJSONBody := extractHandlerMetadata(reqType)
+	dependencies, hasJSONBody := extractHandlerMetadata(reqType, tags)
 
 	return &SSECompiledHandler{
 		handlerFunc:  handlerValue,
@@ -82,13 +148,24 @@ func compileSSEHandler(handler interface{}) (*SSECompiledHandler, error) {
 		respType:     respType,
 		extractors:   extractors,
 		validators:   validators,
+		fieldSources: compileFieldSources(reqType, extractors),
 		dependencies: dependencies,
 		hasJSONBody:  hasJSONBody,
+		bodyFields:   compileBodyFields(extractors),
 	}, nil
 }
 
 // Execute runs the compiled SSE handler
 func (sh *SSECompiledHandler) Execute(ctx context.Context, w http.ResponseWriter, r *http.Request, depResolver *DependencyResolver, errorHandler ErrorHandler) {
+	// Once events are being sent a panic can only end the stream
+	streaming := false
+	cfg := sh.config.Load()
+	defer recoverPanic(w, r, cfg.log(), func(w http.ResponseWriter, r *http.Request, err error) {
+		if !streaming {
+			errorHandler(w, r, err)
+		}
+	})
+
 	// Set SSE headers
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
@@ -97,12 +174,18 @@ func (sh *SSECompiledHandler) Execute(ctx context.Context, w http.ResponseWriter
 	w.Header().Set("Access-Control-Allow-Headers", "Cache-Control")
 
 	// Get the request struct using shared logic
-	reqValue, _, err := sh.prepareRequest(ctx, r, depResolver)
+	reqValue, resolved, err := sh.prepareRequest(ctx, r, depResolver, cfg)
+	resolved.applyHeaders(w.Header(), err)
 	if err != nil {
 		errorHandler(w, r, err)
 		return
 	}
 
+	// Track the stream until it ends, however it ends, so CloseAllSSE can cancel it
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer sh.streams.add(cancel)()
+
 	// Call the handler
 	results := sh.handlerFunc.Call([]reflect.Value{
 		reflect.ValueOf(ctx),
@@ -123,20 +206,38 @@ func (sh *SSECompiledHandler) Execute(ctx context.Context, w http.ResponseWriter
 	}
 
 	// Start streaming
-	sh.streamEvents(ctx, w, iterValue)
+	streaming = true
+	sh.streamEvents(ctx, w, r, iterValue, cfg.log())
 }
 
 // prepareRequest prepares the request struct
-func (sh *SSECompiledHandler) prepareRequest(ctx context.Context, r *http.Request, depResolver *DependencyResolver) (reflect.Value, *ResolvedDependencies, error) {
+func (sh *SSECompiledHandler) prepareRequest(ctx context.Context, r *http.Request, depResolver *DependencyResolver, cfg *routerConfig) (reflect.Value, *ResolvedDependencies, error) {
+	if sh.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, sh.timeout)
+		defer cancel()
+	}
+
+	if err := checkContentType(r, sh.hasJSONBody, sh.contentType); err != nil {
+		return reflect.Value{}, nil, err
+	}
+
 	// Read body once if needed
 	var body []byte
 	var err error
 	if sh.hasJSONBody && r.Body != nil {
-		body, err = io.ReadAll(r.Body)
+		defer r.Body.Close()
+		body, err = readBody(r, cfg.maxBodySize)
 		if err != nil {
-			return reflect.Value{}, nil, fmt.Errorf("failed to read request body: %w", err)
+			return reflect.Value{}, nil, err
+		}
+	}
+
+	// Reject unknown body fields in strict mode, before any dependency runs
+	if sh.strictBody {
+		if unknown := findUnknownBodyFields(body, sh.bodyFields); len(unknown) > 0 {
+			return reflect.Value{}, nil, NewValidationErrorWithStatus(cfg.validationStatus, unknown)
 		}
-		defer r.Body.Close()
 	}
 
 	// Extract path variables
@@ -150,28 +251,47 @@ func (sh *SSECompiledHandler) prepareRequest(ctx context.Context, r *http.Reques
 		values: make(map[string]interface{}),
 	}
 
+	// Resolve RequireAnyOf groups before the fields that may reference them
+	if err := depResolver.resolveAnyOf(ctx, sh.anyOf, r, vars, body, resolved); err != nil {
+		return reflect.Value{}, resolved, err
+	}
+
 	// Extract all fields using shared logic
-	err = extractFields(ctx, reqValue, sh.extractors, sh.dependencies, r, vars, body, depResolver, resolved)
+	err = extractFields(ctx, reqValue, sh.extractors, sh.dependencies, r, vars, body, depResolver, cfg, resolved)
 	if err != nil {
-		return reflect.Value{}, nil, err
+		return reflect.Value{}, resolved, err
+	}
+
+	// Normalize tagged string fields before they are validated
+	if err := applyTransforms(reqValue); err != nil {
+		return reflect.Value{}, resolved, err
 	}
 
 	// Validate the request
-	if err := validateStruct(reqValue.Interface(), sh.validators); err != nil {
-		return reflect.Value{}, nil, err
+	if err := validateStruct(reqValue.Interface(), sh.fieldSources, cfg.validationStatus, translatorFor(r)); err != nil {
+		return reflect.Value{}, resolved, err
+	}
+	if err := sh.hooks.beforeHandler(ctx, reqValue.Interface()); err != nil {
+		return reflect.Value{}, resolved, err
 	}
 
 	return reqValue, resolved, nil
 }
 
 // streamEvents handles the actual SSE streaming
-func (sh *SSECompiledHandler) streamEvents(ctx context.Context, w http.ResponseWriter, iterValue reflect.Value) {
+func (sh *SSECompiledHandler) streamEvents(ctx context.Context, w http.ResponseWriter, r *http.Request, iterValue reflect.Value, logger *slog.Logger) {
 	flusher, ok := w.(http.Flusher)
 	if !ok {
 		http.Error(w, "streaming not supported", http.StatusInternalServerError)
 		return
 	}
 
+	// Per-stream counter for auto-assigned event IDs, resuming after Last-Event-ID
+	var eventCounter uint64
+	if lastID, err := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 64); err == nil {
+		eventCounter = lastID
+	}
+
 	// Create a yield function using reflection
 	// The iterator expects: func(yield func(EventData[T]) bool)
 	// We need to create: func(EventData[T]) bool
@@ -191,9 +311,19 @@ func (sh *SSECompiledHandler) streamEvents(ctx context.Context, w http.ResponseW
 
 		eventData := args[0].Interface()
 
-		// Write the SSE event
-		if err := sh.writeSSEEvent(w, eventData); err != nil {
-			fmt.Printf("Error writing SSE event: %v\n", err)
+		var defaultID string
+		if sh.autoEventID {
+			eventCounter++
+			defaultID = strconv.FormatUint(eventCounter, 10)
+		}
+
+		// Write the SSE event, stopping the iterator if the client went away
+		if err := sh.writeSSEEvent(w, eventData, defaultID); err != nil {
+			if isClientDisconnect(ctx, err) {
+				logger.Debug("SSE client disconnected", requestAttrs(r, "error", err)...)
+			} else {
+				logger.Error("failed to write SSE event", requestAttrs(r, "error", err)...)
+			}
 			return []reflect.Value{reflect.ValueOf(false)}
 		}
 
@@ -205,8 +335,19 @@ func (sh *SSECompiledHandler) streamEvents(ctx context.Context, w http.ResponseW
 	iterValue.Call([]reflect.Value{yieldFunc})
 }
 
-// writeSSEEvent writes a single SSE event
-func (sh *SSECompiledHandler) writeSSEEvent(w io.Writer, eventInterface interface{}) error {
+// isClientDisconnect reports whether a write error was caused by the client
+// closing the connection rather than a server-side failure
+func isClientDisconnect(ctx context.Context, err error) bool {
+	return ctx.Err() != nil ||
+		errors.Is(err, context.Canceled) ||
+		errors.Is(err, http.ErrAbortHandler) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, net.ErrClosed)
+}
+
+// writeSSEEvent writes a single SSE event, using defaultID when the event has no ID
+func (sh *SSECompiledHandler) writeSSEEvent(w io.Writer, eventInterface interface{}, defaultID string) error {
 	// Use reflection to extract EventData fields safely
 	eventValue := reflect.ValueOf(eventInterface)
 	if eventValue.Kind() == reflect.Ptr {
@@ -218,7 +359,7 @@ func (sh *SSECompiledHandler) writeSSEEvent(w io.Writer, eventInterface interfac
 	}
 
 	// Extract fields by name (safer than by index)
-	var event, id string
+	var event, id, comment string
 	var retry int
 	var data interface{}
 
@@ -236,6 +377,10 @@ func (sh *SSECompiledHandler) writeSSEEvent(w io.Writer, eventInterface interfac
 			if fieldValue.Kind() == reflect.String {
 				id = fieldValue.String()
 			}
+		case "Comment":
+			if fieldValue.Kind() == reflect.String {
+				comment = fieldValue.String()
+			}
 		case "Retry":
 			if fieldValue.Kind() == reflect.Int {
 				retry = int(fieldValue.Int())
@@ -245,7 +390,18 @@ func (sh *SSECompiledHandler) writeSSEEvent(w io.Writer, eventInterface interfac
 		}
 	}
 
-	// Write SSE fields
+	if id == "" {
+		id = defaultID
+	}
+
+	// Write SSE fields, one comment line per line of the comment
+	if comment != "" {
+		for _, line := range strings.Split(comment, "\n") {
+			if _, err := fmt.Fprintf(w, ": %s\n", line); err != nil {
+				return err
+			}
+		}
+	}
 	if event != "" {
 		if _, err := fmt.Fprintf(w, "event: %s\n", event); err != nil {
 			return err
@@ -0,0 +1,47 @@
+package gofastapi
+
+import (
+	"io/fs"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Static serves files from dir under urlPrefix. Requests are confined to dir:
+// paths containing ".." are rejected. Static routes do not appear in the OpenAPI spec.
+func (r *Router) Static(urlPrefix, dir string) {
+	r.StaticFS(urlPrefix, os.DirFS(dir))
+}
+
+// StaticFS serves files from fsys under urlPrefix, e.g. an embed.FS holding a UI bundle.
+// Content types are derived from file extensions. Global middleware applies.
+func (r *Router) StaticFS(urlPrefix string, fsys fs.FS) {
+	r.mux.PathPrefix(staticPrefix(urlPrefix)).
+		Handler(r.withMiddleware(staticHandler(urlPrefix, fsys), nil, nil)).
+		Methods(http.MethodGet, http.MethodHead)
+}
+
+// Static serves files from dir under the group's prefix joined with urlPrefix
+func (sr *SubRouter) Static(urlPrefix, dir string) {
+	sr.StaticFS(urlPrefix, os.DirFS(dir))
+}
+
+// StaticFS serves files from fsys under the group's prefix joined with urlPrefix.
+// Global and group middleware apply.
+func (sr *SubRouter) StaticFS(urlPrefix string, fsys fs.FS) {
+	fullPrefix := sr.prefix + urlPrefix
+	sr.router.mux.PathPrefix(staticPrefix(fullPrefix)).
+		Handler(sr.router.withMiddleware(staticHandler(fullPrefix, fsys), sr, nil)).
+		Methods(http.MethodGet, http.MethodHead)
+}
+
+// staticPrefix normalizes a URL prefix so it only matches whole path segments
+func staticPrefix(urlPrefix string) string {
+	return strings.TrimSuffix(urlPrefix, "/") + "/"
+}
+
+// staticHandler serves fsys with the URL prefix stripped. fs.FS implementations reject
+// paths that escape their root, and http.FileServer cleans the request path.
+func staticHandler(urlPrefix string, fsys fs.FS) http.Handler {
+	return http.StripPrefix(strings.TrimSuffix(urlPrefix, "/"), http.FileServerFS(fsys))
+}
@@ -0,0 +1,150 @@
+package gofastapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"reflect"
+
+	ut "github.com/go-playground/universal-translator"
+)
+
+// RecordStream decodes a newline-delimited JSON (NDJSON) request body one record at a
+// time, so bulk uploads are never buffered in full. Bind it with the stream tag:
+//
+//	type ImportRequest struct {
+//	    Records *gofastapi.RecordStream[Product] `stream:""`
+//	}
+//
+// The body is decompressed and size-limited like other request bodies. Each record is
+// validated as it is decoded, and null records are rejected. Iteration stops at the
+// first decode or validation error, which Err then returns; handlers should return it
+// as is.
+type RecordStream[T any] struct {
+	decoder *json.Decoder
+	index   int
+	err     error
+	status  int
+	trans   ut.Translator
+}
+
+// recordStream is implemented by *RecordStream[T] for any T
+type recordStream interface {
+	open(body io.Reader, status int, trans ut.Translator)
+	recordType() reflect.Type
+}
+
+var recordStreamType = reflect.TypeOf((*recordStream)(nil)).Elem()
+
+func (s *RecordStream[T]) open(body io.Reader, status int, trans ut.Translator) {
+	s.decoder = json.NewDecoder(body)
+	s.status = status
+	s.trans = trans
+}
+
+func (s *RecordStream[T]) recordType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// All yields decoded records until the body ends or a record fails to decode or validate
+func (s *RecordStream[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if s.decoder == nil || s.err != nil {
+			return
+		}
+		for {
+			var raw json.RawMessage
+			if err := s.decoder.Decode(&raw); err != nil {
+				var apiErr *Error
+				if errors.As(err, &apiErr) {
+					// Oversized or corrupt compressed bodies keep their status
+					s.err = err
+				} else if !errors.Is(err, io.EOF) {
+					s.err = NewError(http.StatusBadRequest, fmt.Sprintf("invalid record %d: %v", s.index, err))
+				}
+				return
+			}
+			if string(raw) == "null" {
+				s.err = NewValidationErrorWithStatus(s.status, map[string][]string{
+					fmt.Sprintf("body[%d]", s.index): {"must not be null"},
+				})
+				return
+			}
+			var record T
+			if err := json.Unmarshal(raw, &record); err != nil {
+				s.err = NewError(http.StatusBadRequest, fmt.Sprintf("invalid record %d: %v", s.index, err))
+				return
+			}
+			if err := applyTransforms(reflect.ValueOf(&record).Elem()); err != nil {
+				s.err = err
+				return
+			}
+			if err := s.validate(record); err != nil {
+				s.err = err
+				return
+			}
+			s.index++
+			if !yield(record) {
+				return
+			}
+		}
+	}
+}
+
+// Err returns the error that stopped iteration, if any
+func (s *RecordStream[T]) Err() error {
+	return s.err
+}
+
+// validate validates a struct record, qualifying error keys with its position, e.g. "body[3].name"
+func (s *RecordStream[T]) validate(record T) error {
+	t := reflect.TypeOf(record)
+	if t == nil || (t.Kind() != reflect.Struct && !(t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct)) {
+		return nil
+	}
+	err := validateStruct(record, nil, s.status, s.trans)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+	fields := make(map[string][]string, len(validationErr.Fields))
+	for key, messages := range validationErr.Fields {
+		fields[fmt.Sprintf("body[%d].%s", s.index, key)] = messages
+	}
+	return NewValidationErrorWithStatus(s.status, fields)
+}
+
+// StreamExtractor binds a RecordStream to the request body
+type StreamExtractor struct {
+	fieldType reflect.Type
+}
+
+// Extract opens the stream with the default validation status and no size limit
+func (e *StreamExtractor) Extract(r *http.Request, vars map[string]string, body []byte) (interface{}, error) {
+	return e.open(r, http.StatusBadRequest, 0)
+}
+
+// open creates a stream over the decompressed request body, limited to maxSize bytes
+// like readBody, reporting validation failures with status
+func (e *StreamExtractor) open(r *http.Request, status int, maxSize int64) (interface{}, error) {
+	body, err := bodyReader(r, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	stream := reflect.New(e.fieldType.Elem())
+	stream.Interface().(recordStream).open(body, status, translatorFor(r))
+	return stream.Interface(), nil
+}
+
+// isRecordStreamType reports whether t is a *RecordStream[T]
+func isRecordStreamType(t reflect.Type) bool {
+	return t.Kind() == reflect.Ptr && t.Implements(recordStreamType)
+}
+
+// streamRecordType returns the record type T of a *RecordStream[T]
+func streamRecordType(t reflect.Type) reflect.Type {
+	return reflect.New(t.Elem()).Interface().(recordStream).recordType()
+}
@@ -0,0 +1,85 @@
+package gofastapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"testing"
+)
+
+type streamProduct struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type importRequest struct {
+	Records *RecordStream[*streamProduct] `stream:""`
+}
+
+func newImportRouter(t *testing.T) *Router {
+	t.Helper()
+	r := New()
+	err := r.POST("/import", func(ctx context.Context, req importRequest) (int, error) {
+		imported := 0
+		for range req.Records.All() {
+			imported++
+		}
+		return imported, req.Records.Err()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestRecordStreamDecompressesBody(t *testing.T) {
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write([]byte("{\"name\":\"a\"}\n{\"name\":\"b\"}\n"))
+	gz.Close()
+
+	r := newImportRouter(t)
+	resp, err := r.TestRequest(http.MethodPost, "/import", compressed.Bytes(),
+		WithTestHeader("Content-Type", "application/x-ndjson"), WithTestHeader("Content-Encoding", "gzip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var imported int
+	if err := resp.DecodeJSON(&imported); err != nil {
+		t.Fatalf("decode %s: %v", resp.Body, err)
+	}
+	if imported != 2 {
+		t.Errorf("imported = %d, want 2", imported)
+	}
+}
+
+func TestRecordStreamBodyLimit(t *testing.T) {
+	r := newImportRouter(t)
+	r.SetMaxBodySize(20)
+	body := "{\"name\":\"a\"}\n{\"name\":\"b\"}\n{\"name\":\"c\"}\n"
+	resp, err := r.TestRequest(http.MethodPost, "/import", body, WithTestHeader("Content-Type", "application/x-ndjson"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want 413; body: %s", resp.StatusCode, resp.Body)
+	}
+}
+
+func TestRecordStreamRejectsNullRecords(t *testing.T) {
+	r := newImportRouter(t)
+	resp, err := r.TestRequest(http.MethodPost, "/import", "{\"name\":\"a\"}\nnull\n", WithTestHeader("Content-Type", "application/x-ndjson"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400; body: %s", resp.StatusCode, resp.Body)
+	}
+	var body ErrorResponse
+	if err := resp.DecodeJSON(&body); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := body.Fields["body[1]"]; !ok {
+		t.Errorf("fields = %v, want body[1] reported", body.Fields)
+	}
+}
@@ -0,0 +1,102 @@
+package gofastapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+)
+
+// TestOption configures a request built by TestRequest
+type TestOption func(*http.Request)
+
+// WithTestHeader sets a header on the test request
+func WithTestHeader(key, value string) TestOption {
+	return func(req *http.Request) {
+		req.Header.Set(key, value)
+	}
+}
+
+// WithTestContext sets the context of the test request
+func WithTestContext(ctx context.Context) TestOption {
+	return func(req *http.Request) {
+		*req = *req.WithContext(ctx)
+	}
+}
+
+// TestResponse holds the recorded result of a TestRequest
+type TestResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// DecodeJSON decodes the response body into v
+func (tr *TestResponse) DecodeJSON(v interface{}) error {
+	return json.Unmarshal(tr.Body, v)
+}
+
+// TestRequest sends a synthesized request through the full router pipeline
+// (middleware, extraction, validation, dependencies) and records the response.
+// body may be nil, a []byte, a string, an io.Reader, or any value to encode as JSON.
+func (r *Router) TestRequest(method, path string, body interface{}, opts ...TestOption) (*TestResponse, error) {
+	var bodyReader io.Reader
+	isJSON := false
+	switch b := body.(type) {
+	case nil:
+	case []byte:
+		bodyReader = bytes.NewReader(b)
+	case string:
+		bodyReader = bytes.NewBufferString(b)
+	case io.Reader:
+		bodyReader = b
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode test request body: %w", err)
+		}
+		bodyReader = bytes.NewReader(data)
+		isJSON = true
+	}
+
+	// httptest.NewRequest panics on a malformed method or path; report it instead
+	req, err := http.NewRequest(method, path, bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build test request: %w", err)
+	}
+	// Match the server-side request httptest.NewRequest would build
+	req.RequestURI = req.URL.RequestURI()
+	req.RemoteAddr = "192.0.2.1:1234"
+	if req.Host == "" {
+		req.Host = "example.com"
+	}
+	if req.URL.Scheme == "https" {
+		req.TLS = &tls.ConnectionState{Version: tls.VersionTLS12, HandshakeComplete: true, ServerName: req.Host}
+	}
+	if isJSON {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for _, opt := range opts {
+		opt(req)
+	}
+
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, req)
+
+	result := recorder.Result()
+	defer result.Body.Close()
+	respBody, err := io.ReadAll(result.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read test response body: %w", err)
+	}
+
+	return &TestResponse{
+		StatusCode: result.StatusCode,
+		Header:     result.Header,
+		Body:       respBody,
+	}, nil
+}
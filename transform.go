@@ -0,0 +1,204 @@
+package gofastapi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// TransformFunc rewrites a string value before validation, e.g. to trim whitespace
+type TransformFunc func(string) string
+
+var (
+	transforms = map[string]TransformFunc{
+		"trim":  strings.TrimSpace,
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+		"title": titleCase,
+	}
+	transformsMu sync.RWMutex
+
+	// transformPlans caches the compiled transforms of struct types
+	transformPlans sync.Map // reflect.Type -> *transformPlan
+)
+
+// RegisterTransform adds a transform usable in transform tags, alongside the built-in
+// trim, lower, upper and title. Transforms are process-wide and apply to every router;
+// register them before the routes that use them.
+func RegisterTransform(name string, fn TransformFunc) {
+	transformsMu.Lock()
+	defer transformsMu.Unlock()
+	transforms[name] = fn
+}
+
+// titleCase upper-cases the first letter of each space-separated word and
+// lower-cases the rest
+func titleCase(s string) string {
+	runes := []rune(s)
+	start := true
+	for i, r := range runes {
+		if unicode.IsSpace(r) {
+			start = true
+			continue
+		}
+		if start {
+			runes[i] = unicode.ToUpper(r)
+		} else {
+			runes[i] = unicode.ToLower(r)
+		}
+		start = false
+	}
+	return string(runes)
+}
+
+// transformPlan lists the fields of a struct type that are transformed, directly or
+// within nested structs
+type transformPlan struct {
+	fields []fieldTransform
+}
+
+type fieldTransform struct {
+	index  int
+	funcs  []TransformFunc // Applied in order to string values of the field
+	nested *transformPlan  // Plan of the struct the field holds, if it has transforms
+}
+
+// compileTransforms returns the transform plan of struct type t, or nil if none of its
+// fields, including those of nested structs, have a transform tag. Unknown transforms
+// and transform tags on non-string fields are errors.
+func compileTransforms(t reflect.Type) (*transformPlan, error) {
+	return compileTransformsOf(t, make(map[reflect.Type]*transformPlan))
+}
+
+func compileTransformsOf(t reflect.Type, visiting map[reflect.Type]*transformPlan) (*transformPlan, error) {
+	if cached, ok := transformPlans.Load(t); ok {
+		return cached.(*transformPlan), nil
+	}
+	if plan, ok := visiting[t]; ok {
+		return plan, nil // Self-referential type; the plan is being filled in
+	}
+
+	plan := &transformPlan{}
+	visiting[t] = plan
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		// Dependency results belong to the dependency and may be shared
+		if field.PkgPath != "" || field.Tag.Get("dep") != "" {
+			continue
+		}
+		ft := fieldTransform{index: i}
+
+		if tag := field.Tag.Get("transform"); tag != "" && tag != "-" {
+			if elem := transformElem(field.Type); elem.Kind() != reflect.String {
+				return nil, fmt.Errorf("field %s: transform tag requires a string, *string or []string field", field.Name)
+			}
+			for _, name := range strings.Split(tag, ",") {
+				name = strings.TrimSpace(name)
+				transformsMu.RLock()
+				fn, ok := transforms[name]
+				transformsMu.RUnlock()
+				if !ok {
+					return nil, fmt.Errorf("field %s: unknown transform %q", field.Name, name)
+				}
+				ft.funcs = append(ft.funcs, fn)
+			}
+		} else if elem := transformElem(field.Type); elem.Kind() == reflect.Struct {
+			nested, err := compileTransformsOf(elem, visiting)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", field.Name, err)
+			}
+			ft.nested = nested
+		}
+
+		if len(ft.funcs) > 0 || ft.nested != nil {
+			plan.fields = append(plan.fields, ft)
+		}
+	}
+	delete(visiting, t)
+
+	if len(plan.fields) == 0 {
+		plan = nil
+	}
+	transformPlans.Store(t, plan)
+	return plan, nil
+}
+
+// transformElem strips pointers, slices and arrays from t, e.g. []*Address -> Address
+func transformElem(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	return t
+}
+
+// applyTransforms transforms the tagged string fields of v, a struct or pointer to one,
+// in place. v must be addressable.
+func applyTransforms(v reflect.Value) error {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	plan, err := compileTransforms(v.Type())
+	if err != nil {
+		return err
+	}
+	plan.apply(v)
+	return nil
+}
+
+func (p *transformPlan) apply(v reflect.Value) {
+	if p == nil {
+		return
+	}
+	for _, ft := range p.fields {
+		field := v.Field(ft.index)
+		if len(ft.funcs) > 0 {
+			transformStrings(field, ft.funcs)
+		} else {
+			ft.nested.applyNested(field)
+		}
+	}
+}
+
+// applyNested applies the plan to a struct held directly, by pointer or in a slice
+func (p *transformPlan) applyNested(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			p.applyNested(v.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			p.applyNested(v.Index(i))
+		}
+	case reflect.Struct:
+		p.apply(v)
+	}
+}
+
+// transformStrings applies funcs to a string held directly, by pointer or in a slice
+func transformStrings(v reflect.Value, funcs []TransformFunc) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			transformStrings(v.Elem(), funcs)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			transformStrings(v.Index(i), funcs)
+		}
+	case reflect.String:
+		s := v.String()
+		for _, fn := range funcs {
+			s = fn(s)
+		}
+		v.SetString(s)
+	}
+}
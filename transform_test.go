@@ -0,0 +1,75 @@
+package gofastapi
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type transformAddress struct {
+	City string `json:"city" transform:"trim,title"`
+}
+
+type transformSignup struct {
+	Email   string           `json:"email" transform:"trim,lower" validate:"required,email"`
+	Name    *string          `json:"name" transform:"collapse_spaces"`
+	Tags    []string         `json:"tags" transform:"upper"`
+	Address transformAddress `json:"address"`
+}
+
+func TestTransformsRunBeforeValidation(t *testing.T) {
+	RegisterTransform("collapse_spaces", func(s string) string { return strings.Join(strings.Fields(s), " ") })
+	r := New()
+	err := r.POST("/signup", func(ctx context.Context, req transformSignup) (transformSignup, error) {
+		return req, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := `{"email":"  Alice@Example.COM ","name":" Alice   Smith ","tags":["go","api"],"address":{"city":" new york "}}`
+	resp, err := r.TestRequest(http.MethodPost, "/signup", []byte(body), WithTestHeader("Content-Type", "application/json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200 once the email is trimmed; body: %s", resp.StatusCode, resp.Body)
+	}
+	var got transformSignup
+	if err := resp.DecodeJSON(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Email != "alice@example.com" {
+		t.Errorf("email = %q, want alice@example.com", got.Email)
+	}
+	if got.Name == nil || *got.Name != "Alice Smith" {
+		t.Errorf("name = %v, want Alice Smith", got.Name)
+	}
+	if strings.Join(got.Tags, ",") != "GO,API" {
+		t.Errorf("tags = %v, want [GO API]", got.Tags)
+	}
+	if got.Address.City != "New York" {
+		t.Errorf("city = %q, want New York", got.Address.City)
+	}
+}
+
+func TestInvalidTransformsFailRegistration(t *testing.T) {
+	r := New()
+	err := r.GET("/unknown", func(ctx context.Context, req struct {
+		Q string `query:"q" transform:"reverse"`
+	}) (string, error) {
+		return req.Q, nil
+	})
+	if err == nil {
+		t.Error("unknown transform was accepted")
+	}
+	err = r.GET("/number", func(ctx context.Context, req struct {
+		N int `query:"n" transform:"trim"`
+	}) (int, error) {
+		return req.N, nil
+	})
+	if err == nil {
+		t.Error("transform on an int field was accepted")
+	}
+}
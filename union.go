@@ -0,0 +1,117 @@
+package gofastapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// unionType describes an interface type whose JSON values are one of several concrete
+// types, selected by the value of a discriminator property
+type unionType struct {
+	discriminator string
+	variants      map[string]reflect.Type // Discriminator value -> concrete type
+	values        []string                // Discriminator values, sorted
+}
+
+var (
+	unions   = map[reflect.Type]*unionType{}
+	unionsMu sync.RWMutex
+)
+
+// RegisterUnionType is like RegisterUnion for an interface type only known at run time
+func RegisterUnionType(iface reflect.Type, discriminator string, variants map[string]interface{}) error {
+	if iface == nil || iface.Kind() != reflect.Interface {
+		return fmt.Errorf("union type must be an interface, got %v", iface)
+	}
+	if discriminator == "" {
+		return fmt.Errorf("union %s: discriminator property is required", iface)
+	}
+	if len(variants) == 0 {
+		return fmt.Errorf("union %s: at least one variant is required", iface)
+	}
+
+	union := &unionType{discriminator: discriminator, variants: make(map[string]reflect.Type)}
+	for value, variant := range variants {
+		t := reflect.TypeOf(variant)
+		if t == nil || !t.Implements(iface) {
+			return fmt.Errorf("union %s: variant %q (%v) does not implement it", iface, value, t)
+		}
+		if t.Kind() != reflect.Struct && (t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct) {
+			return fmt.Errorf("union %s: variant %q must be a struct or struct pointer", iface, value)
+		}
+		union.variants[value] = t
+		union.values = append(union.values, value)
+	}
+	slices.Sort(union.values)
+
+	unionsMu.Lock()
+	defer unionsMu.Unlock()
+	unions[iface] = union
+	return nil
+}
+
+// lookupUnion returns the union registered for t, if any
+func lookupUnion(t reflect.Type) (*unionType, bool) {
+	unionsMu.RLock()
+	defer unionsMu.RUnlock()
+	union, ok := unions[t]
+	return union, ok
+}
+
+// variantOf returns the variant named by the discriminator of the JSON object data
+func (u *unionType) variantOf(data []byte) (reflect.Type, string, bool) {
+	var object map[string]json.RawMessage
+	if json.Unmarshal(data, &object) != nil {
+		return nil, "", false
+	}
+	var value string
+	if json.Unmarshal(object[u.discriminator], &value) != nil {
+		return nil, "", false
+	}
+	variant, ok := u.variants[value]
+	return variant, value, ok
+}
+
+// decode unmarshals the JSON object data into the variant named by its discriminator.
+// path names the value in errors, e.g. "body.payment".
+func (u *unionType) decode(data []byte, path string) (reflect.Value, error) {
+	variant, value, ok := u.variantOf(data)
+	if !ok {
+		field := path + "." + u.discriminator
+		message := fmt.Sprintf("%s must be one of: %s", field, strings.Join(u.values, ", "))
+		if value == "" {
+			message = fmt.Sprintf("%s is required, one of: %s", field, strings.Join(u.values, ", "))
+		}
+		return reflect.Value{}, NewErrorWithCode(http.StatusBadRequest, "INVALID_DISCRIMINATOR", message)
+	}
+
+	result := reflect.New(variant)
+	if err := json.Unmarshal(data, result.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+	return result.Elem(), nil
+}
+
+// RegisterUnion declares the concrete types the JSON body interface type T may hold,
+// keyed by the value of the discriminator property. Request fields of that type are
+// decoded into the variant named by the discriminator and documented as a oneOf with
+// a discriminator. Unions are process-wide and apply to every router:
+//
+//	type Payment interface{ isPayment() }
+//
+//	gofastapi.RegisterUnion[Payment]("type", map[string]Payment{
+//	    "card": CardPayment{},
+//	    "bank": BankPayment{},
+//	})
+func RegisterUnion[T any](discriminator string, variants map[string]T) error {
+	values := make(map[string]interface{}, len(variants))
+	for value, variant := range variants {
+		values[value] = variant
+	}
+	return RegisterUnionType(reflect.TypeOf((*T)(nil)).Elem(), discriminator, values)
+}
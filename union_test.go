@@ -0,0 +1,104 @@
+package gofastapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+type unionPayment interface{ isUnionPayment() }
+
+type unionCard struct {
+	Type   string `json:"type"`
+	Number string `json:"number"`
+}
+
+type unionBank struct {
+	Type string `json:"type"`
+	IBAN string `json:"iban"`
+}
+
+func (unionCard) isUnionPayment() {}
+func (unionBank) isUnionPayment() {}
+
+func TestUnionBodyField(t *testing.T) {
+	err := RegisterUnion[unionPayment]("type", map[string]unionPayment{
+		"card": unionCard{},
+		"bank": unionBank{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := New()
+	err = r.POST("/payments", func(ctx context.Context, req struct {
+		Payment unionPayment `json:"payment"`
+	}) (string, error) {
+		return fmt.Sprintf("%T", req.Payment), nil
+	}, WithStrictBody())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		want       string
+	}{
+		{"card", `{"payment":{"type":"card","number":"4242"}}`, http.StatusOK, "gofastapi.unionCard"},
+		{"bank", `{"payment":{"type":"bank","iban":"DE89"}}`, http.StatusOK, "gofastapi.unionBank"},
+		{"unknown variant", `{"payment":{"type":"cash"}}`, http.StatusBadRequest, "INVALID_DISCRIMINATOR"},
+		{"missing discriminator", `{"payment":{"number":"4242"}}`, http.StatusBadRequest, "INVALID_DISCRIMINATOR"},
+		{"field of another variant", `{"payment":{"type":"card","iban":"DE89"}}`, http.StatusBadRequest, "VALIDATION_ERROR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := r.TestRequest(http.MethodPost, "/payments", []byte(tt.body), WithTestHeader("Content-Type", "application/json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body: %s", resp.StatusCode, tt.wantStatus, resp.Body)
+			}
+			if tt.wantStatus == http.StatusOK {
+				var got string
+				if err := resp.DecodeJSON(&got); err != nil {
+					t.Fatal(err)
+				}
+				if got != tt.want {
+					t.Errorf("payment decoded as %s, want %s", got, tt.want)
+				}
+				return
+			}
+			var errResp ErrorResponse
+			if err := resp.DecodeJSON(&errResp); err != nil {
+				t.Fatal(err)
+			}
+			if errResp.Code != tt.want {
+				t.Errorf("code = %q, want %q", errResp.Code, tt.want)
+			}
+		})
+	}
+
+	spec := r.GenerateOpenAPISpec()
+	schema := spec.Components.Schemas["unionPayment"]
+	if schema == nil || len(schema.OneOf) != 2 || schema.Discriminator == nil || schema.Discriminator.PropertyName != "type" {
+		t.Fatalf("union schema = %+v, want a oneOf of both variants discriminated by type", schema)
+	}
+	if ref := schema.Discriminator.Mapping["card"]; ref != "#/components/schemas/unionCard" {
+		t.Errorf("card mapping = %q, want #/components/schemas/unionCard", ref)
+	}
+}
+
+func TestRegisterUnionRejectsInvalidVariants(t *testing.T) {
+	if err := RegisterUnion[unionPayment]("", map[string]unionPayment{"card": unionCard{}}); err == nil {
+		t.Error("empty discriminator was accepted")
+	}
+	if err := RegisterUnion[unionPayment]("type", nil); err == nil {
+		t.Error("union without variants was accepted")
+	}
+	if err := RegisterUnion[string]("type", map[string]string{"a": "a"}); err == nil {
+		t.Error("non-interface union type was accepted")
+	}
+}
@@ -2,8 +2,12 @@ package gofastapi
 
 import (
 	"fmt"
+	"reflect"
+	"slices"
+	"strings"
 	"sync"
 
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
 )
 
@@ -12,6 +16,41 @@ var (
 	validatorOnce     sync.Once
 )
 
+// ValidationMessageFunc formats the message reported for a failed validation rule
+type ValidationMessageFunc func(fieldErr validator.FieldError) string
+
+var (
+	validationMessages   = make(map[string]ValidationMessageFunc)
+	validationMessagesMu sync.RWMutex
+)
+
+// RegisterValidationMessage sets how failures of a validation tag are reported in
+// ValidationError.Fields, e.g. to centralize wording or emit localization keys. The
+// messages are process-wide and apply to every router.
+func RegisterValidationMessage(tag string, fn ValidationMessageFunc) {
+	validationMessagesMu.Lock()
+	defer validationMessagesMu.Unlock()
+	validationMessages[tag] = fn
+}
+
+// validationMessage formats a field error using the registered formatter for its tag,
+// then the translator (if any), falling back to the default message
+func validationMessage(fieldErr validator.FieldError, trans ut.Translator) string {
+	validationMessagesMu.RLock()
+	fn, ok := validationMessages[fieldErr.Tag()]
+	validationMessagesMu.RUnlock()
+	if ok {
+		return fn(fieldErr)
+	}
+	if trans != nil {
+		// Translate returns the raw error text when the tag has no translation
+		if msg := fieldErr.Translate(trans); msg != fieldErr.Error() {
+			return msg
+		}
+	}
+	return fmt.Sprintf("failed %s validation", fieldErr.Tag())
+}
+
 // AddValidationRule adds a custom validation rule to the validator
 func addValidationRule(tag string, fn validator.Func) error {
 	v := getValidator()
@@ -27,22 +66,66 @@ func getValidator() *validator.Validate {
 	return validatorInstance
 }
 
-// validateStruct validates a struct using the validator tags
-func validateStruct(obj interface{}, fieldValidators map[int]string) error {
+// validateStruct validates a struct using the validator tags.
+// Error keys are namespaced by their source (e.g. "body.title") using fieldSources,
+// and messages are localized with trans when it is non-nil.
+func validateStruct(obj interface{}, fieldSources map[string]string, status int, trans ut.Translator) error {
 	v := getValidator()
 
+	fields := make(map[string][]string)
+
 	// If we have field-level validators, we need to validate the entire struct
 	if err := v.Struct(obj); err != nil {
-		if validationErrors, ok := err.(validator.ValidationErrors); ok {
-			fields := make(map[string][]string)
-			for _, fieldErr := range validationErrors {
-				fields[fieldErr.Field()] = append(fields[fieldErr.Field()],
-					fmt.Sprintf("failed %s validation", fieldErr.Tag()))
-			}
-			return NewValidationError(fields)
+		validationErrors, ok := err.(validator.ValidationErrors)
+		if !ok {
+			return err
 		}
-		return err
+		for _, fieldErr := range validationErrors {
+			key := validationErrorKey(fieldErr, fieldSources)
+			fields[key] = append(fields[key], validationMessage(fieldErr, trans))
+		}
+	}
+
+	// Reject values outside the set declared by Enum types
+	enumErrors := make(map[string][]string)
+	collectEnumErrors(reflect.ValueOf(obj), "", enumErrors)
+	for path, messages := range enumErrors {
+		key := qualifyFieldPath(strings.Split(path, "."), fieldSources)
+		fields[key] = append(fields[key], messages...)
 	}
 
+	if len(fields) > 0 {
+		return NewValidationErrorWithStatus(status, fields)
+	}
 	return nil
 }
+
+// validationErrorKey builds the error key for a field error, replacing the top-level
+// struct field with its source-qualified name
+func validationErrorKey(fieldErr validator.FieldError, fieldSources map[string]string) string {
+	// Namespace is "Struct.Field[.Nested...]"; drop the root struct name
+	parts := strings.Split(fieldErr.StructNamespace(), ".")
+	if len(parts) < 2 {
+		return fieldErr.Field()
+	}
+	return qualifyFieldPath(parts[1:], fieldSources)
+}
+
+// qualifyFieldPath joins a field path relative to the request struct, replacing
+// the longest leading field path with a source, e.g. "Tags" or "Pagination.Page",
+// with its source-qualified name
+func qualifyFieldPath(parts []string, fieldSources map[string]string) string {
+	for n := len(parts); n > 0; n-- {
+		// Slice/map indexes are attached to the field name, e.g. "Tags[0]"
+		lastField, index, _ := strings.Cut(parts[n-1], "[")
+		source, ok := fieldSources[strings.Join(append(slices.Clone(parts[:n-1]), lastField), ".")]
+		if !ok {
+			continue
+		}
+		if index != "" {
+			source += "[" + index
+		}
+		return strings.Join(append([]string{source}, parts[n:]...), ".")
+	}
+	return strings.Join(parts, ".")
+}
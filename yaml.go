@@ -0,0 +1,70 @@
+package gofastapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MarshalOpenAPIYAML returns the OpenAPI spec as YAML. The spec is converted from its
+// JSON encoding, so custom marshalers (extensions, $ref-only parameters and responses)
+// apply and fields keep the order they have in JSON.
+func (r *Router) MarshalOpenAPIYAML() ([]byte, error) {
+	data, err := json.Marshal(r.GenerateOpenAPISpec())
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON is valid YAML; decoding into a node keeps key order and string types
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	useBlockStyle(&node)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ServeOpenAPIYAML serves the OpenAPI spec as YAML at the specified path
+func (r *Router) ServeOpenAPIYAML(path string) {
+	r.mux.Handle(path, r.withMiddleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		data, err := r.MarshalOpenAPIYAML()
+		if err != nil {
+			http.Error(w, "Failed to generate OpenAPI YAML", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/yaml")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Write(data)
+	}), nil, nil)).Methods(http.MethodGet)
+}
+
+// useBlockStyle clears the flow and quoting styles decoded from JSON, letting the
+// encoder emit idiomatic block YAML. Strings that would read as other types, such
+// as the "200" response keys, are still quoted since their nodes keep the !!str tag.
+func useBlockStyle(node *yaml.Node) {
+	node.Style = 0
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" && yaml11Bools[strings.ToLower(node.Value)] {
+		node.Style = yaml.DoubleQuotedStyle // YAML 1.1 parsers would read these as booleans
+	}
+	for _, child := range node.Content {
+		useBlockStyle(child)
+	}
+}
+
+// yaml11Bools are the plain scalars YAML 1.1 resolves to booleans besides true and false
+var yaml11Bools = map[string]bool{
+	"y": true, "yes": true, "n": true, "no": true, "on": true, "off": true,
+}